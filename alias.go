@@ -0,0 +1,49 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrNotAlias is returned by ParseAlias for lines that are not an
+	// alias command.
+	ErrNotAlias = errors.New("not an alias definition")
+
+	// ErrInvalidAlias is returned by ParseAlias for alias commands that
+	// do not define exactly one alias as name=value.
+	ErrInvalidAlias = errors.New("invalid alias definition")
+)
+
+// ParseAlias parses an alias definition such as `alias ll='ls -la'`,
+// returning the alias name and its value with quoting removed.
+//
+// The value may use any quoting Split understands. For example, the value of
+//
+//	alias say='echo '\''hi'\'''
+//
+// is `echo 'hi'`. The name itself must not be quoted. A trailing comment is
+// ignored.
+func ParseAlias(line string) (name, value string, err error) {
+	words, err := splitWords(line, nil)
+	if err != nil {
+		return "", "", err
+	}
+	if len(words) == 0 || words[0].value != "alias" {
+		return "", "", ErrNotAlias
+	}
+	if len(words) != 2 {
+		return "", "", ErrInvalidAlias
+	}
+
+	def := words[1]
+	i := strings.IndexByte(def.value, '=')
+	if i <= 0 || i >= def.bare {
+		return "", "", ErrInvalidAlias
+	}
+	return def.value[:i], def.value[i+1:], nil
+}
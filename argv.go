@@ -0,0 +1,116 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+var (
+	// ErrArgTooLong is returned by ValidateArgv for an argument longer
+	// than the operating system allows.
+	ErrArgTooLong = errors.New("argument too long")
+
+	// ErrArgvTooLong is returned by ValidateArgv for an argv whose total
+	// length exceeds what the operating system allows.
+	ErrArgvTooLong = errors.New("argument list too long")
+)
+
+// ArgvError is returned by ValidateArgv, recording which argument is invalid.
+type ArgvError struct {
+	// Index is the index of the invalid argument in argv. For
+	// ErrArgvTooLong, it is the first argument that does not fit, or -1
+	// on Windows, where the limit is on the whole command line. It is -1
+	// for ErrEmptyCommand.
+	Index int
+
+	// Err is ErrEmptyCommand, ErrNUL, ErrArgTooLong or ErrArgvTooLong.
+	Err error
+}
+
+func (e *ArgvError) Error() string {
+	if e.Index < 0 {
+		return fmt.Sprintf("argv: %v", e.Err)
+	}
+	return fmt.Sprintf("argument %d: %v", e.Index, e.Err)
+}
+
+func (e *ArgvError) Unwrap() error {
+	return e.Err
+}
+
+// argvLimits are the exec limits of an operating system. Zero means there is
+// no limit.
+type argvLimits struct {
+	// maxArg is the maximum length of a single argument, and maxTotal that
+	// of all arguments, in bytes with their terminating NULs.
+	maxArg   int
+	maxTotal int
+
+	// maxCommandLine is the maximum length of the command line built by
+	// JoinWindows, in UTF-16 code units with its terminating NUL.
+	maxCommandLine int
+}
+
+func limitsFor(goos string) argvLimits {
+	switch goos {
+	case "linux", "android":
+		// MAX_ARG_STRLEN, and ARG_MAX for the default 8 MiB stack.
+		return argvLimits{maxArg: 32 * 4096, maxTotal: 2 << 20}
+	case "darwin", "ios":
+		return argvLimits{maxTotal: 1 << 20}
+	case "windows":
+		// The limit of CreateProcess.
+		return argvLimits{maxCommandLine: 32767}
+	case "plan9", "js", "wasip1":
+		return argvLimits{}
+	}
+	// The smallest ARG_MAX of the BSDs, Solaris and AIX.
+	return argvLimits{maxTotal: 256 << 10}
+}
+
+// ValidateArgv checks that argv can be passed to exec on the current
+// operating system. It is ValidateArgvFor(runtime.GOOS, argv).
+func ValidateArgv(argv []string) error {
+	return ValidateArgvFor(runtime.GOOS, argv)
+}
+
+// ValidateArgvFor checks that argv can be passed to exec on the operating
+// system goos, as named by runtime.GOOS. The error is an *ArgvError naming
+// the first invalid argument.
+//
+// argv must not be empty, and no argument may contain a NUL byte. Arguments
+// must also fit the length limits of goos, as far as they are fixed: on
+// Linux, 128 KiB per argument and 2 MiB in total; on macOS, 1 MiB in total;
+// on other Unix systems, 256 KiB in total; and on Windows, 32767 UTF-16 code
+// units for the command line built by JoinWindows. The environment counts
+// towards the total on Unix systems, and some systems allow more depending
+// on their configuration, so the limits are an approximation.
+func ValidateArgvFor(goos string, argv []string) error {
+	if len(argv) == 0 {
+		return &ArgvError{Index: -1, Err: ErrEmptyCommand}
+	}
+	limits := limitsFor(goos)
+	total := 0
+	for i, arg := range argv {
+		if strings.IndexByte(arg, 0) >= 0 {
+			return &ArgvError{Index: i, Err: ErrNUL}
+		}
+		if limits.maxArg > 0 && len(arg)+1 > limits.maxArg {
+			return &ArgvError{Index: i, Err: ErrArgTooLong}
+		}
+		total += len(arg) + 1
+		if limits.maxTotal > 0 && total > limits.maxTotal {
+			return &ArgvError{Index: i, Err: ErrArgvTooLong}
+		}
+	}
+	if limits.maxCommandLine > 0 && utf16Len(JoinWindows(argv))+1 > limits.maxCommandLine {
+		return &ArgvError{Index: -1, Err: ErrArgvTooLong}
+	}
+	return nil
+}
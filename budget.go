@@ -0,0 +1,87 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"fmt"
+)
+
+// Limit identifies which part of a Budget was exceeded.
+type Limit uint8
+
+const (
+	// ByteLimit is the limit on input bytes.
+	ByteLimit Limit = iota
+
+	// TokenLimit is the limit on the number of words.
+	TokenLimit
+
+	// TokenLenLimit is the limit on the input bytes of a word.
+	TokenLenLimit
+)
+
+func (l Limit) String() string {
+	switch l {
+	case ByteLimit:
+		return "bytes"
+	case TokenLimit:
+		return "tokens"
+	case TokenLenLimit:
+		return "bytes per token"
+	}
+	return fmt.Sprintf("Limit(%d)", uint8(l))
+}
+
+// Budget bounds the work done by SplitBudget on untrusted input. A zero field
+// means that quantity is unlimited.
+type Budget struct {
+	// MaxBytes is the maximum number of input bytes to process.
+	MaxBytes int
+
+	// MaxTokens is the maximum number of words to produce.
+	MaxTokens int
+}
+
+// BudgetError is returned when splitting exceeds a Budget, or a limit set by
+// WithMaxTokens or WithMaxTokenLen.
+type BudgetError struct {
+	// Limit is the exceeded limit.
+	Limit Limit
+
+	// Max is the configured maximum for Limit.
+	Max int
+
+	// Offset is the byte offset in the input at which splitting stopped.
+	Offset int
+}
+
+func (e *BudgetError) Error() string {
+	return fmt.Sprintf("offset %d: exceeded limit of %d %s", e.Offset, e.Max, e.Limit)
+}
+
+// SplitBudget is like Split, but stops with a *BudgetError as soon as it
+// has processed more than b.MaxBytes bytes of s or would produce more than
+// b.MaxTokens words. A zero field of b leaves the limit set by opts, such as
+// WithMaxTokens, in place.
+func SplitBudget(s string, b Budget, opts ...Option) ([]string, error) {
+	cfg := newConfig(opts)
+	if b.MaxBytes > 0 {
+		cfg.maxBytes = b.MaxBytes
+	}
+	if b.MaxTokens > 0 {
+		cfg.maxTokens = b.MaxTokens
+	}
+
+	words, err := lexString(s, &cfg)
+	if _, ok := err.(*BudgetError); ok {
+		return nil, err
+	}
+
+	ret := []string{}
+	for _, w := range words {
+		ret = append(ret, w.value)
+	}
+	return ret, nil
+}
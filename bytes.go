@@ -0,0 +1,40 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"bytes"
+)
+
+// SplitBytes is like Split, but reads from and returns byte slices, so that
+// lines read from buffers or memory-mapped files need not be converted to
+// strings first.
+//
+// The words share a single newly allocated buffer; b is not retained. To read
+// words one at a time, use NewLexer(bytes.NewReader(b)).
+func SplitBytes(b []byte, opts ...Option) [][]byte {
+	cfg := newConfig(opts)
+	sc := newScanner(bytes.NewReader(b), &cfg)
+
+	var words []word
+	n := 0
+	for {
+		w, err := sc.next()
+		if err != nil {
+			break
+		}
+		words = append(words, w)
+		n += len(w.value)
+	}
+
+	buf := make([]byte, 0, n)
+	ret := make([][]byte, 0, len(words))
+	for _, w := range words {
+		start := len(buf)
+		buf = append(buf, w.value...)
+		ret = append(ret, buf[start:len(buf):len(buf)])
+	}
+	return ret
+}
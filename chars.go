@@ -0,0 +1,40 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"unicode"
+)
+
+// Metacharacters are the characters that, unquoted, make up shell operators
+// such as | and &&. Split only treats them specially WithOperators, but Quote
+// always quotes them.
+const Metacharacters = "|&;<>()"
+
+// IsBlank reports whether r separates words when unquoted. Split treats all
+// Unicode white space as blanks, as defined by unicode.IsSpace, and not only
+// spaces, tabs and newlines.
+func IsBlank(r rune) bool {
+	return unicode.IsSpace(r)
+}
+
+// IsMetacharacter reports whether r is one of Metacharacters.
+func IsMetacharacter(r rune) bool {
+	return isMeta(r)
+}
+
+// IsSafe reports whether r never needs quoting: ASCII letters and digits,
+// and @ % + = : , . / - _. Quote leaves words of only these characters
+// bare.
+func IsSafe(r rune) bool {
+	return isSafe(r)
+}
+
+// NeedsQuoting reports whether s must be quoted to survive Split as a single
+// argument unchanged, that is, whether Quote(s) differs from s. The empty
+// string needs quoting.
+func NeedsQuoting(s string) bool {
+	return needsQuoting(s)
+}
@@ -0,0 +1,27 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"strings"
+)
+
+// ParseCmdline returns the arguments of a process command line, as read from
+// /proc/<pid>/cmdline or printed by ps -o args.
+//
+// If s contains a NUL byte, it is taken to be NUL-separated, as in
+// /proc/<pid>/cmdline: it is split at each NUL, ignoring one trailing NUL,
+// and nul is true. Otherwise s is taken to be a flattened command line and is
+// split by Split.
+//
+// Note that ps does not quote arguments, so arguments of a flattened line
+// that contain blanks or quotes cannot be recovered exactly.
+func ParseCmdline(s string) (args []string, nul bool) {
+	if strings.IndexByte(s, 0) < 0 {
+		return Split(s), false
+	}
+	s = strings.TrimSuffix(s, "\x00")
+	return strings.Split(s, "\x00"), true
+}
@@ -0,0 +1,173 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ErrEmptyCommand is returned by a CommandWriter for an empty argv, which
+// cannot be told apart from a blank line.
+var ErrEmptyCommand = errors.New("empty command")
+
+// CommandReader reads files with one shell-quoted command per line, as used
+// by job queues and the like. Commands are split as by Split.
+//
+// Blank lines and lines holding only a comment are skipped. A newline within
+// quotes does not end the command, so commands written by a CommandWriter
+// read back unchanged.
+type CommandReader struct {
+	br   *bufio.Reader
+	opts []Option
+	cfg  config
+
+	// pos is the position of the start of the next line.
+	pos Position
+}
+
+// NewCommandReader returns a CommandReader reading from r. The options are
+// used to split each command.
+func NewCommandReader(r io.Reader, opts ...Option) *CommandReader {
+	cfg := newConfig(opts)
+	pos := Position{Source: cfg.source, Line: 1, Column: 1}
+	if cfg.utf16 {
+		pos.UTF16Column = 1
+	}
+	return &CommandReader{
+		br:   bufio.NewReader(r),
+		opts: opts,
+		cfg:  cfg,
+		pos:  pos,
+	}
+}
+
+// Read returns the next command, or io.EOF at the end of the input.
+//
+// If the input ends within quotes or with a trailing backslash, or a command
+// exceeds a limit such as WithMaxTokens, Read returns a *ParseError whose
+// position is relative to the whole input, so that its Line is the line
+// number in the file.
+func (r *CommandReader) Read() ([]string, error) {
+	for {
+		start := r.pos
+		var record string
+		for {
+			line, err := r.br.ReadString('\n')
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+			if len(line) == 0 {
+				if len(record) == 0 {
+					return nil, io.EOF
+				}
+				return nil, r.parseError(record, start)
+			}
+			r.advance(line)
+			record += line
+
+			words, serr := splitWords(strings.TrimSuffix(record, "\n"), r.opts)
+			if serr == nil {
+				if len(words) == 0 {
+					break
+				}
+				return values(words), nil
+			}
+			if (serr == ErrUnterminatedSingleQuote || serr == ErrUnterminatedDoubleQuote) && err != io.EOF {
+				// The newline is within quotes; read on.
+				continue
+			}
+			return nil, r.parseError(record, start)
+		}
+	}
+}
+
+// ReadAll reads all remaining commands.
+func (r *CommandReader) ReadAll() ([][]string, error) {
+	var cmds [][]string
+	for {
+		argv, err := r.Read()
+		if err == io.EOF {
+			return cmds, nil
+		}
+		if err != nil {
+			return cmds, err
+		}
+		cmds = append(cmds, argv)
+	}
+}
+
+// advance moves pos past line, which ends with a newline unless it is the
+// last line of the input.
+func (r *CommandReader) advance(line string) {
+	r.pos.Offset += len(line)
+	if r.cfg.utf16 {
+		r.pos.UTF16Offset += utf16Len(line)
+	}
+	if strings.HasSuffix(line, "\n") {
+		r.pos.Line++
+	}
+}
+
+// parseError returns the *ParseError for the malformed record, which starts
+// at start.
+func (r *CommandReader) parseError(record string, start Position) error {
+	l := NewLexer(strings.NewReader(strings.TrimSuffix(record, "\n")), r.opts...)
+	for {
+		_, err := l.Next()
+		pe, ok := err.(*ParseError)
+		if !ok {
+			if err == nil {
+				continue
+			}
+			return err
+		}
+		p := pe.Pos
+		p.Offset += start.Offset
+		p.UTF16Offset += start.UTF16Offset
+		p.Line += start.Line - 1
+		return &ParseError{Pos: p, Err: pe.Err}
+	}
+}
+
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n++
+		if r >= 0x10000 {
+			n++
+		}
+	}
+	return n
+}
+
+// CommandWriter writes commands one per line, quoted by Join, in the format
+// read by CommandReader.
+type CommandWriter struct {
+	w io.Writer
+}
+
+// NewCommandWriter returns a CommandWriter writing to w.
+func NewCommandWriter(w io.Writer) *CommandWriter {
+	return &CommandWriter{w: w}
+}
+
+// Write writes argv as a single command. It returns ErrEmptyCommand if argv
+// is empty and ErrNUL if an argument contains a NUL byte, neither of which
+// would read back unchanged.
+func (w *CommandWriter) Write(argv []string) error {
+	if len(argv) == 0 {
+		return ErrEmptyCommand
+	}
+	for _, arg := range argv {
+		if strings.IndexByte(arg, 0) >= 0 {
+			return ErrNUL
+		}
+	}
+	_, err := io.WriteString(w.w, Join(argv)+"\n")
+	return err
+}
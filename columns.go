@@ -0,0 +1,60 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// FormatColumns renders argv as a command that is broken into lines of at
+// most width characters, for display in a terminal. Arguments are quoted as
+// by Quote and are never broken; an argument too long to fit is put on a line
+// of its own, which is then longer than width.
+//
+// Lines after the first are indented by two spaces, and all lines but the
+// last end with a backslash, so that the lines still form a valid command. A
+// width of zero or less puts everything on one line.
+func FormatColumns(argv []string, width int) []string {
+	const (
+		indent = "  "
+		cont   = " \\"
+	)
+
+	var lines []string
+	var line strings.Builder
+
+	// n is the length of line, and words the number of arguments on it.
+	n, words := 0, 0
+	for i, arg := range argv {
+		q := Quote(arg)
+		qn := utf8.RuneCountInString(q)
+
+		// Leave room for the continuation unless this is the last
+		// argument.
+		need := n + 1 + qn
+		if i < len(argv)-1 {
+			need += len(cont)
+		}
+		if words > 0 && width > 0 && need > width {
+			line.WriteString(cont)
+			lines = append(lines, line.String())
+			line.Reset()
+			line.WriteString(indent)
+			n, words = len(indent), 0
+		}
+		if words > 0 {
+			line.WriteByte(' ')
+			n++
+		}
+		line.WriteString(q)
+		n += qn
+		words++
+	}
+	if words > 0 {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
@@ -0,0 +1,215 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// isName reports whether s is a valid shell variable name.
+func isName(s string) bool {
+	if len(s) == 0 || !isNameStart(s[0]) {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		if !isNameChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isAssignment reports whether w is a variable assignment such as FOO=bar.
+// The name and = must not be quoted or escaped: 'FOO=bar' and FOO\=bar are
+// ordinary words.
+func (w word) isAssignment() bool {
+	i := strings.IndexByte(w.value, '=')
+	return i > 0 && i < w.bare && isName(w.value[:i])
+}
+
+// EnvPrefix splits line into the variable assignments preceding the command
+// and the command itself.
+//
+// For example, `FOO=1 BAR="a b" make all` returns env ["FOO=1", "BAR=a b"]
+// and args ["make", "all"].
+func EnvPrefix(line string) (env []string, args []string) {
+	words, _ := splitWords(line, nil)
+	i := 0
+	for i < len(words) && words[i].isAssignment() {
+		env = append(env, words[i].value)
+		i++
+	}
+	for _, w := range words[i:] {
+		args = append(args, w.value)
+	}
+	return env, args
+}
+
+// Assignment is a variable assignment preceding a command, as returned by
+// EnvPrefixSpans.
+type Assignment struct {
+	// Name is the variable name, and Value the value with quotes removed.
+	Name  string
+	Value string
+
+	// Start and End are the byte offsets of the assignment in the line,
+	// e.g. of `BAR="a b"`, quotes included.
+	Start int
+	End   int
+}
+
+// EnvPrefixSpans is like EnvPrefix, but also returns where each assignment
+// and the command are in line, so that they can be edited in place. The
+// assignments are in the order they were written.
+//
+// The command spans from start to end, up to the end of its last word. If
+// line has no command, start and end are -1.
+func EnvPrefixSpans(line string) (env []Assignment, args []string, start, end int) {
+	words, _ := splitWords(line, nil)
+	i := 0
+	for i < len(words) && words[i].isAssignment() {
+		w := words[i]
+		eq := strings.IndexByte(w.value, '=')
+		env = append(env, Assignment{
+			Name:  w.value[:eq],
+			Value: w.value[eq+1:],
+			Start: w.start,
+			End:   w.end,
+		})
+		i++
+	}
+	if i == len(words) {
+		return env, nil, -1, -1
+	}
+	for _, w := range words[i:] {
+		args = append(args, w.value)
+	}
+	return env, args, words[i].start, words[len(words)-1].end
+}
+
+// CommandName returns the base name of the command run by line, with quotes
+// removed and leading variable assignments skipped.
+//
+// For example, `LANG=C '/usr/bin/python3' -c x` returns "python3". If line
+// has no command, CommandName returns "".
+func CommandName(line string) string {
+	_, args := EnvPrefix(line)
+	if len(args) == 0 || len(args[0]) == 0 {
+		return ""
+	}
+	return path.Base(args[0])
+}
+
+// IsSingleWord reports whether s is exactly one word under Split, with no
+// surrounding whitespace, comment, unterminated quote, or unquoted operator
+// character (| & ; < > ( )). The word may still be expanded by a shell, as
+// with $HOME or *.go, which RequiresShell reports.
+//
+// The empty string is not a single word, but a quoted empty string is.
+func IsSingleWord(s string) bool {
+	words, err := splitWords(s, nil)
+	if err != nil || len(words) != 1 {
+		return false
+	}
+	w := words[0]
+	return w.start == 0 && w.end == len(s) && !w.meta
+}
+
+// splitsDifferently reports whether gap, the text between two words if
+// between is set, or before the first or after the last word otherwise,
+// separates words differently for a shell than for Split: with a newline,
+// which ends a command, or with white space other than blanks, which the
+// shell keeps in words.
+func splitsDifferently(gap string, between bool) bool {
+	for i := 0; i < len(gap); {
+		r, size := utf8.DecodeRuneInString(gap[i:])
+		switch {
+		case r == '#':
+			// A comment, up to the newline.
+			if i = indexByteFrom(gap, i, '\n'); i < 0 {
+				return false
+			}
+			continue
+		case r == '\n':
+			if between {
+				return true
+			}
+		case r != ' ' && r != '\t' && unicode.IsSpace(r):
+			return true
+		}
+		i += size
+	}
+	return false
+}
+
+// dollarQuoted reports whether the word raw has $'...' or $"..." quoting,
+// which Split does not decode as a shell would.
+func dollarQuoted(raw string) bool {
+	for _, p := range (Token{Raw: raw}).QuoteParts() {
+		if strings.HasPrefix(p.Raw, "$'") || strings.HasPrefix(p.Raw, `$"`) {
+			return true
+		}
+	}
+	return false
+}
+
+// shellOnly are the reserved words and builtins that only exist within a
+// shell, and have no executable counterpart.
+var shellOnly = map[string]struct{}{
+	"!": {}, "{": {}, "}": {}, "[[": {}, "]]": {}, "case": {}, "do": {},
+	"done": {}, "elif": {}, "else": {}, "esac": {}, "fi": {}, "for": {},
+	"function": {}, "if": {}, "in": {}, "select": {}, "then": {}, "time": {},
+	"until": {}, "while": {},
+
+	".": {}, "alias": {}, "bg": {}, "cd": {}, "declare": {}, "eval": {},
+	"exec": {}, "exit": {}, "export": {}, "fg": {}, "history": {}, "jobs": {},
+	"local": {}, "read": {}, "readonly": {}, "return": {}, "set": {},
+	"shift": {}, "source": {}, "trap": {}, "typeset": {}, "ulimit": {},
+	"umask": {}, "unalias": {}, "unset": {}, "wait": {},
+}
+
+// RequiresShell reports whether line must be run by a shell such as sh -c,
+// rather than executed directly as the argv returned by Split.
+//
+// A line requires a shell if it contains unquoted operators or redirections,
+// expansions, globs, brace or tilde expansion, $'...' or $"..." quoting,
+// leading variable assignments, an unterminated quote, a newline between
+// words or a backslash before a newline, white space other than spaces, tabs
+// and newlines outside of quotes, or if its command is a shell reserved word
+// or builtin such as cd or export.
+func RequiresShell(line string) bool {
+	words, err := splitWords(line, nil)
+	if err != nil {
+		return true
+	}
+	prev := 0
+	for i, w := range words {
+		raw := line[w.start:w.end]
+		if w.meta || w.glob || w.brace || w.tilde || dollarQuoted(raw) || strings.Contains(raw, "\\\n") {
+			return true
+		}
+		if splitsDifferently(line[prev:w.start], i > 0) {
+			return true
+		}
+		prev = w.end
+	}
+	if splitsDifferently(line[prev:], false) {
+		return true
+	}
+	if exps, err := wordExpansions(line, words); err != nil || len(exps) > 0 {
+		return true
+	}
+	if len(words) == 0 {
+		return false
+	}
+	if words[0].isAssignment() {
+		return true
+	}
+	_, ok := shellOnly[words[0].value]
+	return ok
+}
@@ -0,0 +1,69 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrTrailingComment is returned by Concat for a fragment that ends within a
+// comment, which would swallow the fragments after it.
+var ErrTrailingComment = errors.New("trailing comment")
+
+// Concat joins fragments of a command line, such as pieces read from a
+// configuration file, separating them with a space, so that the words of
+// the result are those of the fragments in order. Empty fragments are
+// skipped.
+//
+// Each fragment must be complete on its own: Concat fails if a fragment ends
+// within quotes, with a trailing backslash, or, unless it is the last one,
+// within a comment. The error is one of ErrUnterminatedSingleQuote,
+// ErrUnterminatedDoubleQuote, ErrTrailingBackslash and ErrTrailingComment,
+// prefixed with the index of the fragment.
+func Concat(fragments ...string) (string, error) {
+	last := len(fragments) - 1
+	for last >= 0 && len(fragments[last]) == 0 {
+		last--
+	}
+
+	var b strings.Builder
+	for i, f := range fragments {
+		if len(f) == 0 {
+			continue
+		}
+		if err := checkFragment(f, i == last); err != nil {
+			return "", fmt.Errorf("fragment %d: %w", i, err)
+		}
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(f)
+	}
+	return b.String(), nil
+}
+
+func checkFragment(f string, last bool) error {
+	var cfg config
+	sc := newScanner(strings.NewReader(f), &cfg)
+	for {
+		_, err := sc.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := sc.unterminated(); err != nil {
+		return err
+	}
+	if sc.context == comment && !last {
+		return ErrTrailingComment
+	}
+	return nil
+}
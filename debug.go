@@ -0,0 +1,66 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"encoding/json"
+	"io"
+)
+
+type jsonSegment struct {
+	Kind string `json:"kind"`
+	Raw  string `json:"raw"`
+}
+
+type jsonToken struct {
+	Value    string        `json:"value"`
+	Raw      string        `json:"raw"`
+	Kind     string        `json:"kind"`
+	Start    int           `json:"start"`
+	End      int           `json:"end"`
+	Line     int           `json:"line"`
+	Column   int           `json:"column"`
+	Segments []jsonSegment `json:"segments"`
+}
+
+type jsonError struct {
+	Error string `json:"error"`
+}
+
+// DumpTokens writes the tokens of s, as found by Lex with opts, to w as JSON
+// Lines: one object per token, with its value, raw text, kind such as "word"
+// or "operator", byte span, line, column and quoting segments. It is meant
+// for debugging, e.g. by piping the output to jq.
+//
+// If lexing fails, a final object with an "error" member is written and the
+// error is returned as well. The format may change between versions.
+func DumpTokens(w io.Writer, s string, opts ...Option) error {
+	tokens, lexErr := Lex(s, opts...)
+	enc := json.NewEncoder(w)
+	for _, t := range tokens {
+		jt := jsonToken{
+			Value:    t.Value,
+			Raw:      t.Raw,
+			Kind:     t.Kind.String(),
+			Start:    t.Pos.Offset,
+			End:      t.Pos.Offset + len(t.Raw),
+			Line:     t.Pos.Line,
+			Column:   t.Pos.Column,
+			Segments: []jsonSegment{},
+		}
+		for _, seg := range t.Segments() {
+			jt.Segments = append(jt.Segments, jsonSegment{Kind: seg.Kind.String(), Raw: seg.Raw})
+		}
+		if err := enc.Encode(jt); err != nil {
+			return err
+		}
+	}
+	if lexErr != nil {
+		if err := enc.Encode(jsonError{Error: lexErr.Error()}); err != nil {
+			return err
+		}
+	}
+	return lexErr
+}
@@ -0,0 +1,160 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"fmt"
+)
+
+// Dialect is a shell language.
+type Dialect uint8
+
+const (
+	// DialectPOSIX is the POSIX.1-2017 shell command language, as
+	// implemented by dash and busybox sh.
+	DialectPOSIX Dialect = iota
+
+	// DialectBash is GNU Bash.
+	DialectBash
+
+	// DialectZsh is the Z shell.
+	DialectZsh
+
+	// DialectKsh is the Korn shell, ksh93.
+	DialectKsh
+
+	// DialectFish is the fish shell.
+	DialectFish
+)
+
+func (d Dialect) String() string {
+	switch d {
+	case DialectPOSIX:
+		return "posix"
+	case DialectBash:
+		return "bash"
+	case DialectZsh:
+		return "zsh"
+	case DialectKsh:
+		return "ksh"
+	case DialectFish:
+		return "fish"
+	}
+	return fmt.Sprintf("Dialect(%d)", uint8(d))
+}
+
+// Feature is a construct that not every dialect supports.
+type Feature uint8
+
+const (
+	// FeatureCommandSubstitution is $(...) command substitution.
+	FeatureCommandSubstitution Feature = iota
+
+	// FeatureArithmeticExpansion is $((...)) arithmetic expansion.
+	FeatureArithmeticExpansion
+
+	// FeatureHeredoc is the << here-document.
+	FeatureHeredoc
+
+	// FeatureHereString is the <<< here-string.
+	FeatureHereString
+
+	// FeatureProcessSubstitution is <(...) and >(...) process
+	// substitution.
+	FeatureProcessSubstitution
+
+	// FeatureANSICQuoting is $'...' quoting with escape sequences.
+	FeatureANSICQuoting
+
+	// FeatureLocaleQuoting is $"..." quoting translated by the locale.
+	FeatureLocaleQuoting
+
+	// FeatureBraceExpansion is brace expansion, such as a{b,c}.
+	FeatureBraceExpansion
+
+	// FeatureArrays is array variables and their subscripts.
+	FeatureArrays
+
+	// FeatureExtendedTest is the [[ ... ]] conditional command.
+	FeatureExtendedTest
+
+	// FeatureRedirectAll is &>, redirecting both output and errors.
+	FeatureRedirectAll
+
+	// FeaturePipeAll is |&, piping both output and errors.
+	FeaturePipeAll
+)
+
+func (f Feature) String() string {
+	switch f {
+	case FeatureCommandSubstitution:
+		return "command substitution"
+	case FeatureArithmeticExpansion:
+		return "arithmetic expansion"
+	case FeatureHeredoc:
+		return "here-document"
+	case FeatureHereString:
+		return "here-string"
+	case FeatureProcessSubstitution:
+		return "process substitution"
+	case FeatureANSICQuoting:
+		return "ANSI-C quoting"
+	case FeatureLocaleQuoting:
+		return "locale quoting"
+	case FeatureBraceExpansion:
+		return "brace expansion"
+	case FeatureArrays:
+		return "arrays"
+	case FeatureExtendedTest:
+		return "extended test"
+	case FeatureRedirectAll:
+		return "&> redirection"
+	case FeaturePipeAll:
+		return "|& pipe"
+	}
+	return fmt.Sprintf("Feature(%d)", uint8(f))
+}
+
+// features lists the dialects supporting each feature, indexed by Feature.
+var features = [...][]Dialect{
+	FeatureCommandSubstitution: {DialectPOSIX, DialectBash, DialectZsh, DialectKsh, DialectFish},
+	FeatureArithmeticExpansion: {DialectPOSIX, DialectBash, DialectZsh, DialectKsh},
+	FeatureHeredoc:             {DialectPOSIX, DialectBash, DialectZsh, DialectKsh},
+	FeatureHereString:          {DialectBash, DialectZsh, DialectKsh},
+	FeatureProcessSubstitution: {DialectBash, DialectZsh, DialectKsh},
+	FeatureANSICQuoting:        {DialectBash, DialectZsh, DialectKsh},
+	FeatureLocaleQuoting:       {DialectBash, DialectKsh},
+	FeatureBraceExpansion:      {DialectBash, DialectZsh, DialectKsh, DialectFish},
+	FeatureArrays:              {DialectBash, DialectZsh, DialectKsh, DialectFish},
+	FeatureExtendedTest:        {DialectBash, DialectZsh, DialectKsh},
+	FeatureRedirectAll:         {DialectBash, DialectZsh, DialectFish},
+	FeaturePipeAll:             {DialectBash, DialectZsh},
+}
+
+// Supports reports whether the dialect supports f.
+func (d Dialect) Supports(f Feature) bool {
+	if int(f) >= len(features) {
+		return false
+	}
+	for _, fd := range features[f] {
+		if fd == d {
+			return true
+		}
+	}
+	return false
+}
+
+// Capabilities returns the features that d supports, in the order of their
+// constants, so that tools can enable or reject constructs according to the
+// shell that will run a command. It returns nil for an unknown dialect.
+func Capabilities(d Dialect) []Feature {
+	var ret []Feature
+	for f := range features {
+		if d.Supports(Feature(f)) {
+			ret = append(ret, Feature(f))
+		}
+	}
+	return ret
+}
@@ -0,0 +1,121 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"fmt"
+)
+
+// EditOp is the kind of an Edit.
+type EditOp uint8
+
+const (
+	// EditInsert inserts an argument of the new argv.
+	EditInsert EditOp = iota
+
+	// EditDelete deletes an argument of the old argv.
+	EditDelete
+
+	// EditReplace replaces an argument of the old argv with one of the new.
+	EditReplace
+)
+
+func (op EditOp) String() string {
+	switch op {
+	case EditInsert:
+		return "insert"
+	case EditDelete:
+		return "delete"
+	case EditReplace:
+		return "replace"
+	}
+	return fmt.Sprintf("EditOp(%d)", uint8(op))
+}
+
+// Edit is a single change between two argvs, as returned by DiffArgv.
+type Edit struct {
+	Op EditOp
+
+	// AIndex is the index of the old argument in a. For EditInsert, it is
+	// the index in a before which New is inserted.
+	AIndex int
+
+	// BIndex is the index of the new argument in b. For EditDelete, it is
+	// the index in b at which Old would have been.
+	BIndex int
+
+	// Old is the deleted or replaced argument, and New the inserted or
+	// replacing one.
+	Old string
+	New string
+}
+
+// String renders the edit with its arguments quoted as by Quote, as "+new",
+// "-old" or "-old +new".
+func (e Edit) String() string {
+	switch e.Op {
+	case EditInsert:
+		return "+" + Quote(e.New)
+	case EditDelete:
+		return "-" + Quote(e.Old)
+	}
+	return "-" + Quote(e.Old) + " +" + Quote(e.New)
+}
+
+// DiffArgv returns a shortest list of edits that turns argv a into argv b,
+// in order of position. Runs of deleted arguments directly followed by
+// inserted ones are paired up into replacements.
+func DiffArgv(a, b []string) []Edit {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var edits []Edit
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		if i < len(a) && j < len(b) && a[i] == b[j] {
+			i++
+			j++
+			continue
+		}
+
+		// Collect the run of changes up to the next common argument.
+		di, dj := i, j
+		for (i < len(a) || j < len(b)) && (i == len(a) || j == len(b) || a[i] != b[j]) {
+			if j == len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1] {
+				i++
+			} else {
+				j++
+			}
+		}
+		for di < i && dj < j {
+			edits = append(edits, Edit{Op: EditReplace, AIndex: di, BIndex: dj, Old: a[di], New: b[dj]})
+			di++
+			dj++
+		}
+		for ; di < i; di++ {
+			edits = append(edits, Edit{Op: EditDelete, AIndex: di, BIndex: dj, Old: a[di]})
+		}
+		for ; dj < j; dj++ {
+			edits = append(edits, Edit{Op: EditInsert, AIndex: di, BIndex: dj, New: b[dj]})
+		}
+	}
+	return edits
+}
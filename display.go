@@ -0,0 +1,97 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DisplayLimits bounds the arguments shown by SanitizeForDisplayLimits. A
+// zero field means that quantity is unlimited.
+type DisplayLimits struct {
+	// MaxArgLen is the maximum number of characters of an argument, once
+	// sanitized. Longer arguments are cut short and end in "…".
+	MaxArgLen int
+
+	// MaxArgs is the maximum number of arguments. If there are more, the
+	// last one shown is replaced by a note such as "… (3 more)".
+	MaxArgs int
+}
+
+// defaultMaxArgLen is the MaxArgLen of SanitizeForDisplay.
+const defaultMaxArgLen = 256
+
+// SanitizeForDisplay returns argv made safe to show in a terminal or web
+// page, as SanitizeForDisplayLimits does with arguments of at most 256
+// characters.
+func SanitizeForDisplay(argv []string) []string {
+	return SanitizeForDisplayLimits(argv, DisplayLimits{MaxArgLen: defaultMaxArgLen})
+}
+
+// SanitizeForDisplayLimits returns argv made safe to show, so that hostile
+// command lines cannot break the layout of a user interface or spoof what is
+// shown. Control characters, such as newlines and terminal escape
+// sequences, invisible format characters, such as bidirectional overrides
+// and zero-width spaces, and invalid UTF-8 are replaced by escapes such as
+// \n, \x1b and \u202e, and arguments are shortened according to l.
+//
+// The result is meant to be read, not parsed: backslashes are kept as is, so
+// an escape cannot be told from the same text in argv. Use Join for a
+// command line that can be split again.
+func SanitizeForDisplayLimits(argv []string, l DisplayLimits) []string {
+	ret := make([]string, 0, len(argv))
+	for i, arg := range argv {
+		if l.MaxArgs > 0 && i == l.MaxArgs-1 && len(argv) > l.MaxArgs {
+			return append(ret, fmt.Sprintf("… (%d more)", len(argv)-i))
+		}
+		ret = append(ret, sanitizeArg(arg, l.MaxArgLen))
+	}
+	return ret
+}
+
+// sanitizeArg escapes arg and cuts it short to max characters, if max is
+// positive, never within an escape.
+func sanitizeArg(arg string, max int) string {
+	var b strings.Builder
+	n := 0
+	for i := 0; i < len(arg); {
+		piece, size := displayRune(arg[i:])
+		i += size
+		pn := utf8.RuneCountInString(piece)
+		// Unless this is the end, leave room for the ellipsis.
+		if max > 0 && (n+pn > max || n+pn == max && i < len(arg)) {
+			b.WriteString("…")
+			break
+		}
+		b.WriteString(piece)
+		n += pn
+	}
+	return b.String()
+}
+
+// displayRune returns the first rune of s, escaped if needed, and its size.
+func displayRune(s string) (string, int) {
+	r, size := utf8.DecodeRuneInString(s)
+	switch {
+	case r == utf8.RuneError && size == 1:
+		return fmt.Sprintf(`\x%02x`, s[0]), size
+	case r == '\t':
+		return `\t`, size
+	case r == '\n':
+		return `\n`, size
+	case r == '\r':
+		return `\r`, size
+	case r < 0x80 && unicode.IsControl(r):
+		return fmt.Sprintf(`\x%02x`, r), size
+	case !unicode.IsControl(r) && !unicode.In(r, unicode.Cf, unicode.Zl, unicode.Zp):
+		return s[:size], size
+	case r > 0xffff:
+		return fmt.Sprintf(`\U%08x`, r), size
+	}
+	return fmt.Sprintf(`\u%04x`, r), size
+}
@@ -0,0 +1,81 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import "strings"
+
+// CommandAt returns the words of the logical command of doc that contains
+// the given line, numbered from 1, along with the byte span [start, end) of
+// the command in doc, excluding its final newline. This is what a "run
+// current line" feature of an editor needs.
+//
+// A logical command continues onto the next line if its line ends with a
+// backslash, which is removed along with the newline, or within quotes. If
+// line is out of range, CommandAt returns nil, -1, -1.
+func CommandAt(doc string, line int) (argv []string, start, end int) {
+	if line < 1 {
+		return nil, -1, -1
+	}
+	// Newlines only end the command outside of words, which includes
+	// escaped ones and those in quotes, but not those after comments.
+	tokens, _ := Lex(doc, WithComments(CommentKeep))
+	n, t := 1, 0
+
+	// joined is the command so far with line continuations removed, up to
+	// doc[from:].
+	var joined []byte
+	from := 0
+	for i := 0; i <= len(doc); i++ {
+		if t < len(tokens) && tokens[t].Pos.Offset == i && len(tokens[t].Raw) > 0 {
+			tok := tokens[t]
+			t++
+			if tok.Kind != TokenComment {
+				joined = appendContinued(append(joined, doc[from:i]...), tok)
+				from = i + len(tok.Raw)
+				n += strings.Count(tok.Raw, "\n")
+			}
+			i += len(tok.Raw) - 1
+			continue
+		}
+		if i < len(doc) && doc[i] != '\n' {
+			continue
+		}
+
+		if n >= line {
+			joined = append(joined, doc[from:i]...)
+			return Split(string(joined)), start, i
+		}
+		if i == len(doc) {
+			break
+		}
+		n++
+		start = i + 1
+		joined, from = joined[:0], start
+	}
+	return nil, -1, -1
+}
+
+// appendContinued appends the Raw of tok to b, removing the backslash-newlines
+// that continue it onto the next line outside of single quotes.
+func appendContinued(b []byte, tok Token) []byte {
+	for _, p := range tok.QuoteParts() {
+		if p.Quoting != QuotingBare && p.Quoting != QuotingDouble {
+			b = append(b, p.Raw...)
+			continue
+		}
+		for i := 0; i < len(p.Raw); i++ {
+			if p.Raw[i] == '\\' && i+1 < len(p.Raw) {
+				if p.Raw[i+1] == '\n' {
+					i++
+					continue
+				}
+				b = append(b, p.Raw[i])
+				i++
+			}
+			b = append(b, p.Raw[i])
+		}
+	}
+	return b
+}
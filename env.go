@@ -0,0 +1,42 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"sort"
+	"strings"
+)
+
+// FormatEnvAssignments returns the quoted KEY=value words for env, sorted by
+// key, such as KEY='a b'. The words are assignments in a shell command prefix
+// as long as each key is a valid variable name; other keys are quoted along
+// with their value, which env still accepts.
+func FormatEnvAssignments(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	words := make([]string, len(keys))
+	for i, k := range keys {
+		if isName(k) {
+			words[i] = k + "=" + Quote(env[k])
+		} else {
+			words[i] = Quote(k + "=" + env[k])
+		}
+	}
+	return words
+}
+
+// JoinEnv returns a command line running argv with env added to its
+// environment, of the form "env KEY=value ... cmd args".
+func JoinEnv(env map[string]string, argv []string) string {
+	words := append([]string{"env"}, FormatEnvAssignments(env)...)
+	if len(argv) > 0 {
+		words = append(words, Join(argv))
+	}
+	return strings.Join(words, " ")
+}
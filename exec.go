@@ -0,0 +1,51 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// FormatCommand returns the shell command line that runs program name with
+// args, with the same arguments as exec.Command. It is quoted by Join, so
+// the same command is always displayed the same way and Split returns name
+// and args.
+func FormatCommand(name string, args ...string) string {
+	argv := make([]string, 0, 1+len(args))
+	argv = append(argv, name)
+	return Join(append(argv, args...))
+}
+
+// FormatCmd returns the shell command line that runs cmd, for audit logs that
+// must be replayable. Unlike cmd.String, it is quoted by Join, so that Split
+// returns cmd.Path followed by cmd.Args[1:].
+//
+// If cmd.Env is set, the command line starts with "env -i" and the entries of
+// cmd.Env as assignments, so that the command runs with exactly that
+// environment, as cmd does; entries without an = are left out, since env
+// cannot pass them. cmd.Dir is not included.
+func FormatCmd(cmd *exec.Cmd) string {
+	var words []string
+	if cmd.Env != nil {
+		words = append(words, "env", "-i")
+		for _, kv := range cmd.Env {
+			i := strings.IndexByte(kv, '=')
+			switch {
+			case i < 0:
+				continue
+			case isName(kv[:i]):
+				words = append(words, kv[:i+1]+Quote(kv[i+1:]))
+			default:
+				words = append(words, Quote(kv))
+			}
+		}
+	}
+	words = append(words, Quote(cmd.Path))
+	if len(cmd.Args) > 1 {
+		words = append(words, Join(cmd.Args[1:]))
+	}
+	return strings.Join(words, " ")
+}
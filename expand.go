@@ -0,0 +1,456 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrCommandSubstitution is returned when a line contains $(...),
+	// `...`, <(...) or >(...) where it is not allowed.
+	ErrCommandSubstitution = errors.New("command substitution not allowed")
+
+	// ErrArithmeticExpansion is returned when a line contains $((...))
+	// where it is not allowed.
+	ErrArithmeticExpansion = errors.New("arithmetic expansion not allowed")
+
+	// ErrVariableNotAllowed is returned when a line references a variable
+	// outside the allowed set.
+	ErrVariableNotAllowed = errors.New("variable not allowed")
+
+	// ErrUnterminatedExpansion is returned when ${, $( or ` is never
+	// closed.
+	ErrUnterminatedExpansion = errors.New("unterminated expansion")
+)
+
+// TemplateError describes an expansion that failed validation.
+type TemplateError struct {
+	// Offset is the byte offset of the expansion in the line.
+	Offset int
+
+	// Expansion is the text of the offending expansion, e.g. "$(id)".
+	Expansion string
+
+	// Err is one of the Err* variables of this package.
+	Err error
+}
+
+func (e *TemplateError) Error() string {
+	return fmt.Sprintf("offset %d: %s: %v", e.Offset, e.Expansion, e.Err)
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+type expansionKind uint8
+
+const (
+	varExpansion expansionKind = iota
+	commandSubstitution
+	arithmeticExpansion
+)
+
+// expansion is a single expansion found in a line. start and end are byte
+// offsets of the whole expansion, including $ and any braces.
+type expansion struct {
+	kind  expansionKind
+	name  string
+	start int
+	end   int
+}
+
+// ValidateTemplate checks that line expands only variables named in
+// allowedVars, and contains no command substitution or arithmetic expansion.
+//
+// Expansions are recognized as Bash would: unquoted or within double quotes,
+// but not within single quotes, comments, or after a backslash. Special
+// parameters such as $1 or $@ must be listed in allowedVars by name ("1",
+// "@") to be allowed.
+//
+// The returned error is a *TemplateError.
+func ValidateTemplate(line string, allowedVars []string) error {
+	exps, err := scanExpansions(line)
+	if err != nil {
+		return err
+	}
+	return validate(line, exps, allowedVars)
+}
+
+// VarSyntax is a syntax for variable references other than the shell's, such
+// as the %VAR% of Windows batch files or the {{var}} of template languages.
+// A reference is Open, the variable name, and Close; blanks around the name
+// are ignored.
+type VarSyntax struct {
+	Open  string
+	Close string
+}
+
+var (
+	// BraceSyntax is the ${var} syntax.
+	BraceSyntax = VarSyntax{Open: "${", Close: "}"}
+
+	// PercentSyntax is the %VAR% syntax.
+	PercentSyntax = VarSyntax{Open: "%", Close: "%"}
+
+	// TemplateSyntax is the {{var}} syntax.
+	TemplateSyntax = VarSyntax{Open: "{{", Close: "}}"}
+)
+
+// ValidateTemplateSyntax is like ValidateTemplate, but recognizes only
+// variable references written in syn, with the same quoting rules. Other
+// expansions, including $VAR, are not recognized and are allowed.
+func ValidateTemplateSyntax(line string, allowedVars []string, syn VarSyntax) error {
+	exps, err := scanVars(line, syn)
+	if err != nil {
+		return err
+	}
+	return validate(line, exps, allowedVars)
+}
+
+func validate(line string, exps []expansion, allowedVars []string) error {
+	allowed := make(map[string]struct{}, len(allowedVars))
+	for _, v := range allowedVars {
+		allowed[v] = struct{}{}
+	}
+	for _, e := range exps {
+		var err error
+		switch e.kind {
+		case commandSubstitution:
+			err = ErrCommandSubstitution
+		case arithmeticExpansion:
+			err = ErrArithmeticExpansion
+		default:
+			if _, ok := allowed[e.name]; !ok {
+				err = ErrVariableNotAllowed
+			}
+		}
+		if err != nil {
+			return &TemplateError{
+				Offset:    e.start,
+				Expansion: line[e.start:e.end],
+				Err:       err,
+			}
+		}
+	}
+	return nil
+}
+
+// scanExpansions returns all expansions in s, in order of appearance.
+// Expansions nested within ${...} follow the expansion containing them.
+func scanExpansions(s string) ([]expansion, error) {
+	var exps []expansion
+	_, err := scanWord(s, 0, 0, false, &exps)
+	return exps, err
+}
+
+// wordExpansions is like scanExpansions, but only scans the parts of line
+// that the tokenizer made into words, so that, for example, what it takes
+// for a comment is left out. words are the words of line from splitWords.
+func wordExpansions(line string, words []word) ([]expansion, error) {
+	b := []byte(line)
+	blank := func(b []byte) {
+		for i, c := range b {
+			if !isShellBlank(c) {
+				b[i] = ' '
+			}
+		}
+	}
+	prev := 0
+	for _, w := range words {
+		blank(b[prev:w.start])
+		prev = w.end
+	}
+	blank(b[prev:])
+
+	exps, err := scanExpansions(string(b))
+	var te *TemplateError
+	if errors.As(err, &te) {
+		te.Expansion = line[te.Offset : te.Offset+len(te.Expansion)]
+	}
+	return exps, err
+}
+
+// scanVars returns all variable references in s written in syn, in order of
+// appearance.
+func scanVars(s string, syn VarSyntax) ([]expansion, error) {
+	var exps []expansion
+	inDouble := false
+	// boundary is set at the start of s and after an unquoted blank, where
+	// a # begins a comment.
+	boundary := true
+	for i := 0; i < len(s); {
+		c := s[i]
+		atStart := boundary
+		boundary = false
+		switch {
+		case len(syn.Open) > 0 && strings.HasPrefix(s[i:], syn.Open):
+			start := i + len(syn.Open)
+			n := strings.Index(s[start:], syn.Close)
+			if n < 0 || len(syn.Close) == 0 {
+				return nil, &TemplateError{Offset: i, Expansion: s[i:], Err: ErrUnterminatedExpansion}
+			}
+			name := strings.TrimSpace(s[start : start+n])
+			end := start + n + len(syn.Close)
+			addExpansion(&exps, varExpansion, name, i, end)
+			i = end
+			continue
+
+		case c == '\\':
+			i += 2
+			continue
+
+		case c == '\'' && !inDouble:
+			end := indexByteFrom(s, i+1, '\'')
+			if end < 0 {
+				end = len(s)
+			}
+			i = end + 1
+			continue
+
+		case c == '$' && !inDouble && i+1 < len(s) && s[i+1] == '\'':
+			i = quoteEnd(s, i+2, '\'', true)
+			continue
+
+		case c == '"':
+			inDouble = !inDouble
+
+		case c == '#' && !inDouble && atStart:
+			end := indexByteFrom(s, i, '\n')
+			if end < 0 {
+				return exps, nil
+			}
+			i = end
+			continue
+
+		case !inDouble && isShellBlank(c):
+			boundary = true
+		}
+		i++
+	}
+	return exps, nil
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || '0' <= c && c <= '9'
+}
+
+func isSpecialParam(c byte) bool {
+	switch c {
+	case '@', '*', '#', '?', '$', '!', '-':
+		return true
+	}
+	return '0' <= c && c <= '9'
+}
+
+// isShellBlank reports whether c separates words for the shell. Split also
+// separates words at other white space, but a # after it does not begin a
+// comment for the shell, so comments are only recognized after these.
+func isShellBlank(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
+
+// scanWord scans s from i for expansions until it finds an unquoted close
+// byte at nesting depth zero, returning the index of that byte. If close is
+// 0, scanWord scans to the end of s. dquoted is set within the braces of a
+// "${...}", where single quotes are literal.
+func scanWord(s string, i int, close byte, dquoted bool, exps *[]expansion) (int, error) {
+	var open byte
+	switch close {
+	case '}':
+		open = '{'
+	case ')':
+		open = '('
+	}
+
+	depth := 0
+	inDouble := false
+	// boundary is set at the start of s and after an unquoted blank, where
+	// a # begins a comment. Escaped and quoted blanks do not count.
+	boundary := i == 0
+	for i < len(s) {
+		c := s[i]
+		atStart := boundary
+		boundary = false
+		switch {
+		case c == '\\':
+			i += 2
+			continue
+
+		case c == '$' && !inDouble && !dquoted && i+1 < len(s) && s[i+1] == '\'':
+			// $'...' is quoted, with backslash escapes.
+			i = quoteEnd(s, i+2, '\'', true)
+			continue
+
+		case c == '\'' && !inDouble && !dquoted:
+			end := indexByteFrom(s, i+1, '\'')
+			if end < 0 {
+				end = len(s)
+			}
+			i = end + 1
+			continue
+
+		case c == '"':
+			inDouble = !inDouble
+
+		case c == '#' && !inDouble && close == 0 && atStart:
+			end := indexByteFrom(s, i, '\n')
+			if end < 0 {
+				return len(s), nil
+			}
+			i = end
+			continue
+
+		case c == '$' || c == '`':
+			end, err := scanExpansion(s, i, inDouble || dquoted, exps)
+			if err != nil {
+				return 0, err
+			}
+			i = end
+			continue
+
+		case (c == '<' || c == '>') && !inDouble && i+1 < len(s) && s[i+1] == '(':
+			end, err := scanWord(s, i+2, ')', false, nil)
+			if err != nil {
+				return 0, unterminated(s, i, err)
+			}
+			addExpansion(exps, commandSubstitution, "", i, end+1)
+			i = end + 1
+			continue
+
+		case inDouble:
+
+		case isShellBlank(c):
+			boundary = true
+
+		case c == open:
+			depth++
+
+		case c == close && close != 0:
+			if depth == 0 {
+				return i, nil
+			}
+			depth--
+		}
+		i++
+	}
+	if close != 0 {
+		return 0, ErrUnterminatedExpansion
+	}
+	return i, nil
+}
+
+func indexByteFrom(s string, i int, c byte) int {
+	for ; i < len(s); i++ {
+		if s[i] == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// unterminated wraps err in a *TemplateError for the expansion starting at
+// s[start], unless err already is one.
+func unterminated(s string, start int, err error) error {
+	var te *TemplateError
+	if errors.As(err, &te) {
+		return err
+	}
+	return &TemplateError{Offset: start, Expansion: s[start:], Err: err}
+}
+
+func addExpansion(exps *[]expansion, kind expansionKind, name string, start, end int) {
+	if exps != nil {
+		*exps = append(*exps, expansion{kind: kind, name: name, start: start, end: end})
+	}
+}
+
+// scanExpansion scans the expansion starting with the $ or ` at s[start],
+// returning the index just past it. inDouble is set if it is within double
+// quotes.
+func scanExpansion(s string, start int, inDouble bool, exps *[]expansion) (int, error) {
+	if s[start] == '`' {
+		for i := start + 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '`':
+				addExpansion(exps, commandSubstitution, "", start, i+1)
+				return i + 1, nil
+			}
+		}
+		return 0, unterminated(s, start, ErrUnterminatedExpansion)
+	}
+
+	i := start + 1
+	if i >= len(s) {
+		return i, nil
+	}
+	switch c := s[i]; {
+	case c == '(':
+		kind := commandSubstitution
+		if i+1 < len(s) && s[i+1] == '(' {
+			kind = arithmeticExpansion
+		}
+		end, err := scanWord(s, i+1, ')', false, nil)
+		if err != nil {
+			return 0, unterminated(s, start, err)
+		}
+		addExpansion(exps, kind, "", start, end+1)
+		return end + 1, nil
+
+	case c == '{':
+		i++
+		// ${#name} is the length of name.
+		if i+1 < len(s) && s[i] == '#' && s[i+1] != '}' {
+			i++
+		}
+		nameStart := i
+		if i < len(s) && isSpecialParam(s[i]) && !isNameStart(s[i]) {
+			i++
+		} else {
+			for i < len(s) && isNameChar(s[i]) {
+				i++
+			}
+		}
+		name := s[nameStart:i]
+
+		// Expansions in the rest of the braces, e.g. ${A:-$B}, are
+		// recorded after this one.
+		var nested []expansion
+		// Within "${...}", single quotes are literal, so they do not
+		// hide the expansions between them.
+		end, err := scanWord(s, i, '}', inDouble, &nested)
+		if err != nil {
+			return 0, unterminated(s, start, err)
+		}
+		addExpansion(exps, varExpansion, name, start, end+1)
+		if exps != nil {
+			*exps = append(*exps, nested...)
+		}
+		return end + 1, nil
+
+	case isNameStart(c):
+		for i < len(s) && isNameChar(s[i]) {
+			i++
+		}
+		addExpansion(exps, varExpansion, s[start+1:i], start, i)
+		return i, nil
+
+	case isSpecialParam(c):
+		addExpansion(exps, varExpansion, s[i:i+1], start, i+1)
+		return i + 1, nil
+	}
+
+	// A lone $ is literal.
+	return i, nil
+}
@@ -0,0 +1,534 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrUnsupportedExpansion is returned by an Expander for parameter expansion
+// operators it does not implement, such as ${VAR%suffix}.
+var ErrUnsupportedExpansion = errors.New("unsupported expansion")
+
+// DefaultMaxDepth is the nesting limit of alias expansions used by an
+// Expander whose MaxDepth is zero.
+const DefaultMaxDepth = 16
+
+// RecursionError is returned by an Expander when alias expansions nest more
+// deeply than its MaxDepth.
+type RecursionError struct {
+	// Name is the alias that would have exceeded the limit.
+	Name string
+
+	// Max is the limit.
+	Max int
+}
+
+func (e *RecursionError) Error() string {
+	return fmt.Sprintf("alias %q: exceeded maximum recursion depth of %d", e.Name, e.Max)
+}
+
+// Expander is the second phase of a two-phase alternative to Split: it
+// expands tokens produced by Lex into fields, calling back into the caller
+// for each expansion.
+//
+// Expansion follows Bash: expansions are recognized unquoted and within double
+// quotes, the results of unquoted expansions are split into fields at
+// whitespace, and unquoted words containing *, ? or [ are pathname patterns.
+// $'...' and $"..." are quotes, with the escapes of $'...' decoded.
+// Tilde, brace and arithmetic expansion are not performed.
+type Expander struct {
+	// Var returns the value of the named variable and whether it is set.
+	// Special parameters are passed by name, e.g. "1" for $1. If Var is
+	// nil, parameter expansions are left as written.
+	//
+	// Supported forms are $NAME, ${NAME}, ${#NAME}, and ${NAME-word},
+	// ${NAME:-word}, ${NAME+word} and ${NAME:+word}.
+	Var func(name string) (string, bool)
+
+	// Subst returns the output of cmd for $(cmd) and `cmd`. Trailing
+	// newlines are removed from the output. If Subst is nil, command
+	// substitutions are left as written.
+	Subst func(cmd string) (string, error)
+
+	// Glob returns the pathnames matching pattern, which uses the syntax
+	// of path.Match. If there are no matches, the field is kept as is. If
+	// Glob is nil, pathname expansion is not performed.
+	Glob func(pattern string) ([]string, error)
+
+	// Alias returns the value of the named alias and whether it is
+	// defined. If Alias is nil, aliases are not expanded.
+	//
+	// As in Bash, an unquoted word in command position that names an alias
+	// is replaced by the tokens of its value, split WithOperators, which
+	// are expanded for aliases in turn. If the value ends with a blank, the
+	// word following it is checked for an alias too. An alias is not
+	// expanded again within its own value, so "ls" may be an alias for
+	// "ls -F".
+	Alias func(name string) (string, bool)
+
+	// MaxDepth limits the nesting of alias expansions. If it is zero,
+	// DefaultMaxDepth is used.
+	MaxDepth int
+}
+
+// Expand expands each token and returns all resulting fields.
+//
+// Alias expansion, if enabled, is performed first. An alias nested more
+// deeply than MaxDepth is reported as a *RecursionError within a *ParseError.
+func (e *Expander) Expand(tokens []Token) ([]string, error) {
+	fields, err := e.ExpandFields(tokens)
+	if err != nil {
+		return nil, err
+	}
+	return fieldValues(fields), nil
+}
+
+// ExpandToken expands a single token into zero or more fields. Errors are
+// returned as a *ParseError positioned at the token.
+func (e *Expander) ExpandToken(t Token) ([]string, error) {
+	fields, err := e.ExpandTokenFields(t)
+	if err != nil {
+		return nil, err
+	}
+	return fieldValues(fields), nil
+}
+
+// ExpandFields is like Expand, but records the origin of the text of each
+// field.
+func (e *Expander) ExpandFields(tokens []Token) ([]Field, error) {
+	if e.Alias != nil {
+		var err error
+		tokens, err = e.aliases(tokens, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ret := []Field{}
+	for _, t := range tokens {
+		fields, err := e.ExpandTokenFields(t)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, fields...)
+	}
+	return ret, nil
+}
+
+// ExpandTokenFields is like ExpandToken, but records the origin of the text
+// of each field.
+func (e *Expander) ExpandTokenFields(t Token) ([]Field, error) {
+	var b fieldBuilder
+	if err := e.walk(&b, t.Raw, false); err != nil {
+		return nil, &ParseError{Pos: t.Pos, Err: err}
+	}
+	b.end()
+
+	ret := []Field{}
+	for _, f := range b.fields {
+		if f.glob && e.Glob != nil {
+			matches, err := e.Glob(f.pattern)
+			if err != nil {
+				return nil, &ParseError{Pos: t.Pos, Err: err}
+			}
+			if len(matches) > 0 {
+				for _, m := range matches {
+					ret = append(ret, Field{
+						Value: m,
+						Parts: []FieldPart{{Value: m, Origin: OriginPathname}},
+					})
+				}
+				continue
+			}
+		}
+		ret = append(ret, Field{Value: f.value, Parts: f.parts})
+	}
+	return ret, nil
+}
+
+// startsCommand reports whether the token following t is in command
+// position.
+func startsCommand(t Token) bool {
+	if !t.Operator {
+		return false
+	}
+	switch t.Raw {
+	case "|", "||", "|&", "&", "&&", ";", ";;", "(":
+		return true
+	}
+	return false
+}
+
+// aliases expands the aliases in tokens. active lists the aliases being
+// expanded, innermost last.
+func (e *Expander) aliases(tokens []Token, active []string) ([]Token, error) {
+	max := e.MaxDepth
+	if max == 0 {
+		max = DefaultMaxDepth
+	}
+
+	var ret []Token
+	command := true
+	for _, t := range tokens {
+		if !command || t.Operator || t.Raw != t.Value || contains(active, t.Value) {
+			ret = append(ret, t)
+			command = startsCommand(t)
+			continue
+		}
+		value, ok := e.Alias(t.Value)
+		if !ok {
+			ret = append(ret, t)
+			command = false
+			continue
+		}
+		if len(active) >= max {
+			return nil, &ParseError{Pos: t.Pos, Err: &RecursionError{Name: t.Value, Max: max}}
+		}
+
+		sub, err := Lex(value, WithOperators(true))
+		if pe, ok := err.(*ParseError); ok {
+			return nil, &ParseError{Pos: t.Pos, Err: pe.Err}
+		}
+		sub, err = e.aliases(sub, append(active[:len(active):len(active)], t.Value))
+		if err != nil {
+			return nil, err
+		}
+		for _, st := range sub {
+			// Report errors at the aliased word.
+			st.Pos = t.Pos
+			ret = append(ret, st)
+		}
+
+		r, _ := utf8.DecodeLastRuneInString(value)
+		command = len(value) > 0 && unicode.IsSpace(r) || len(sub) > 0 && startsCommand(sub[len(sub)-1])
+	}
+	return ret, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+type field struct {
+	value   string
+	parts   []FieldPart
+	pattern string
+	glob    bool
+}
+
+// fieldBuilder collects the fields produced by expanding one word.
+type fieldBuilder struct {
+	fields []field
+
+	value   strings.Builder
+	parts   []FieldPart
+	pattern strings.Builder
+	glob    bool
+
+	// started is set once the current field exists, even if it is empty.
+	started bool
+}
+
+func isIFS(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n'
+}
+
+// lit appends literal text to the current field. Unquoted text may contain
+// pattern characters.
+func (b *fieldBuilder) lit(s string, quoted bool) {
+	b.add(s, quoted, OriginLiteral)
+}
+
+// add appends text of the given origin to the current field.
+func (b *fieldBuilder) add(s string, quoted bool, origin Origin) {
+	b.started = true
+	b.value.WriteString(s)
+	if n := len(b.parts); n > 0 && b.parts[n-1].Origin == origin {
+		b.parts[n-1].Value += s
+	} else if len(s) > 0 {
+		b.parts = append(b.parts, FieldPart{Value: s, Origin: origin})
+	}
+	if !quoted {
+		if strings.ContainsAny(s, "*?[") {
+			b.glob = true
+		}
+		b.pattern.WriteString(s)
+		return
+	}
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', '\\':
+			b.pattern.WriteByte('\\')
+		}
+		b.pattern.WriteRune(r)
+	}
+}
+
+// expanded appends the result of an expansion. Unquoted results are split
+// into fields at whitespace.
+func (b *fieldBuilder) expanded(s string, quoted bool, origin Origin) {
+	if quoted {
+		b.add(s, true, origin)
+		return
+	}
+	fields := strings.FieldsFunc(s, isIFS)
+	if len(s) > 0 {
+		if r, _ := utf8.DecodeRuneInString(s); isIFS(r) {
+			b.end()
+		}
+	}
+	for i, f := range fields {
+		if i > 0 {
+			b.end()
+		}
+		b.add(f, false, origin)
+	}
+	if len(fields) > 0 {
+		if r, _ := utf8.DecodeLastRuneInString(s); isIFS(r) {
+			b.end()
+		}
+	}
+}
+
+// end finishes the current field, if any.
+func (b *fieldBuilder) end() {
+	if !b.started {
+		return
+	}
+	b.fields = append(b.fields, field{
+		value:   b.value.String(),
+		parts:   b.parts,
+		pattern: b.pattern.String(),
+		glob:    b.glob,
+	})
+	b.value.Reset()
+	b.parts = nil
+	b.pattern.Reset()
+	b.glob = false
+	b.started = false
+}
+
+// walk expands the raw word s into b.
+func (e *Expander) walk(b *fieldBuilder, s string, inDouble bool) error {
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\':
+			if i+1 >= len(s) {
+				// A trailing backslash is dropped, as by Split.
+				return nil
+			}
+			_, size := utf8.DecodeRuneInString(s[i+1:])
+			next := s[i+1 : i+1+size]
+			if inDouble && !strings.Contains("$`\"\\\n", next) {
+				b.lit(`\`, true)
+			}
+			b.lit(next, true)
+			i += 1 + size
+
+		case c == '\'' && !inDouble:
+			end := indexByteFrom(s, i+1, '\'')
+			if end < 0 {
+				end = len(s)
+			}
+			b.lit(s[i+1:end], true)
+			i = end + 1
+
+		case c == '"':
+			b.started = true
+			inDouble = !inDouble
+			i++
+
+		case c == '$' && !inDouble && i+1 < len(s) && s[i+1] == '\'':
+			v, end := ansiC(s[i:])
+			b.lit(v, true)
+			i += end
+
+		case c == '$' && !inDouble && i+1 < len(s) && s[i+1] == '"':
+			// $"..." is translated according to the locale, which
+			// in the C locale leaves it as is.
+			i++
+
+		case c == '$' || c == '`':
+			end, err := e.expand(b, s, i, inDouble)
+			if err != nil {
+				return err
+			}
+			i = end
+
+		default:
+			_, size := utf8.DecodeRuneInString(s[i:])
+			b.lit(s[i:i+size], inDouble)
+			i += size
+		}
+	}
+	return nil
+}
+
+// ansiC returns the value of the $'...' string at the start of s, decoded as
+// by WithANSICQuoting, and the index just past it.
+func ansiC(s string) (string, int) {
+	end := quoteEnd(s, 2, '\'', true)
+	cfg := newConfig([]Option{WithANSICQuoting(true)})
+	words, _ := lexString(s[:end], &cfg)
+	if len(words) == 0 {
+		return "", end
+	}
+	return words[0].value, end
+}
+
+// expand expands the $ or ` expression at s[start] into b, returning the
+// index just past it.
+func (e *Expander) expand(b *fieldBuilder, s string, start int, inDouble bool) (int, error) {
+	if s[start] == '`' {
+		var cmd strings.Builder
+		for i := start + 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				if i+1 < len(s) && strings.IndexByte("$`\\", s[i+1]) >= 0 {
+					i++
+				}
+			case '`':
+				return i + 1, e.subst(b, s[start:i+1], cmd.String(), inDouble)
+			}
+			cmd.WriteByte(s[i])
+		}
+		return 0, ErrUnterminatedExpansion
+	}
+
+	i := start + 1
+	if i >= len(s) {
+		b.lit("$", inDouble)
+		return i, nil
+	}
+	switch c := s[i]; {
+	case c == '(':
+		end, err := scanWord(s, i+1, ')', false, nil)
+		if err != nil {
+			return 0, err
+		}
+		raw := s[start : end+1]
+		if strings.HasPrefix(raw, "$((") {
+			// Arithmetic expansion is left as written.
+			b.lit(raw, true)
+			return end + 1, nil
+		}
+		return end + 1, e.subst(b, raw, s[i+1:end], inDouble)
+
+	case c == '{':
+		end, err := scanWord(s, i+1, '}', inDouble, nil)
+		if err != nil {
+			return 0, err
+		}
+		return end + 1, e.param(b, s[start:end+1], s[i+1:end], inDouble)
+
+	case isNameStart(c):
+		for i < len(s) && isNameChar(s[i]) {
+			i++
+		}
+		return i, e.variable(b, s[start:i], s[start+1:i], inDouble)
+
+	case isSpecialParam(c):
+		return i + 1, e.variable(b, s[start:i+1], s[i:i+1], inDouble)
+	}
+
+	// A lone $ is literal.
+	b.lit("$", inDouble)
+	return i, nil
+}
+
+func (e *Expander) subst(b *fieldBuilder, raw, cmd string, inDouble bool) error {
+	if e.Subst == nil {
+		b.lit(raw, true)
+		return nil
+	}
+	out, err := e.Subst(cmd)
+	if err != nil {
+		return err
+	}
+	b.expanded(strings.TrimRight(out, "\n"), inDouble, OriginCommand)
+	return nil
+}
+
+func (e *Expander) variable(b *fieldBuilder, raw, name string, inDouble bool) error {
+	if e.Var == nil {
+		b.lit(raw, true)
+		return nil
+	}
+	v, _ := e.Var(name)
+	b.expanded(v, inDouble, OriginParameter)
+	return nil
+}
+
+// param expands ${body}.
+func (e *Expander) param(b *fieldBuilder, raw, body string, inDouble bool) error {
+	if e.Var == nil {
+		b.lit(raw, true)
+		return nil
+	}
+
+	length := false
+	if len(body) > 1 && body[0] == '#' {
+		length = true
+		body = body[1:]
+	}
+	n := 0
+	if len(body) > 0 && isSpecialParam(body[0]) && !isNameStart(body[0]) {
+		n = 1
+	} else {
+		for n < len(body) && isNameChar(body[n]) {
+			n++
+		}
+	}
+	if n == 0 {
+		return ErrUnsupportedExpansion
+	}
+	name, op := body[:n], body[n:]
+	v, set := e.Var(name)
+
+	if length {
+		if len(op) > 0 {
+			return ErrUnsupportedExpansion
+		}
+		b.expanded(strconv.Itoa(utf8.RuneCountInString(v)), inDouble, OriginParameter)
+		return nil
+	}
+
+	colon := strings.HasPrefix(op, ":")
+	if colon {
+		op = op[1:]
+		set = set && len(v) > 0
+	}
+	switch {
+	case len(op) == 0 && !colon:
+		b.expanded(v, inDouble, OriginParameter)
+	case len(op) == 0:
+		return ErrUnsupportedExpansion
+	case op[0] == '-':
+		if set {
+			b.expanded(v, inDouble, OriginParameter)
+			return nil
+		}
+		return e.walk(b, op[1:], inDouble)
+	case op[0] == '+':
+		if set {
+			return e.walk(b, op[1:], inDouble)
+		}
+	default:
+		return ErrUnsupportedExpansion
+	}
+	return nil
+}
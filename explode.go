@@ -0,0 +1,88 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"strings"
+)
+
+// Explode breaks a one-liner chained with ;, &&, || and | into lines, one
+// command per line, to make long cron entries and the like readable. It is
+// the reverse of Minify.
+//
+// A ; ending a command is dropped. The operators &&, ||, | and |& are kept
+// at the end of their line, and the command continues on the next line,
+// indented by two spaces; & and ;; are kept too. Words are kept as written,
+// so quoting and expansions are unchanged, and operators within command
+// substitutions do not break the line. Runs of blanks become one, and a
+// comment is kept at the end of the last line.
+//
+// If line ends within quotes, Explode returns a *ParseError.
+func Explode(line string) ([]string, error) {
+	spans, err := Spans(line, WithOperators(true))
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	var b strings.Builder
+	indent := ""
+	space := false
+	end := func(next string) {
+		if b.Len() > 0 {
+			lines = append(lines, indent+b.String())
+		}
+		b.Reset()
+		indent = next
+		space = false
+	}
+
+	for _, sp := range spans {
+		if !sp.Token {
+			if b.Len() > 0 && len(sp.Raw) > 0 {
+				space = true
+			}
+			if i := strings.IndexByte(sp.Raw, '#'); i >= 0 && len(lines)+b.Len() > 0 {
+				comment := " " + strings.TrimSpace(strings.SplitN(sp.Raw[i:], "\n", 2)[0])
+				if b.Len() == 0 {
+					// The comment belongs to the last line.
+					lines[len(lines)-1] += comment
+				} else {
+					b.WriteString(comment)
+				}
+			}
+			if strings.Contains(sp.Raw, "\n") && b.Len() > 0 {
+				end("")
+			}
+			continue
+		}
+
+		if _, ok := operators[sp.Raw]; ok {
+			next := ""
+			switch sp.Raw {
+			case ";":
+				end("")
+				continue
+			case "&&", "||", "|", "|&":
+				next = "  "
+				fallthrough
+			case "&", ";;":
+				if b.Len() > 0 {
+					b.WriteByte(' ')
+				}
+				b.WriteString(sp.Raw)
+				end(next)
+				continue
+			}
+		}
+		if space {
+			b.WriteByte(' ')
+		}
+		b.WriteString(sp.Raw)
+		space = false
+	}
+	end("")
+	return lines, nil
+}
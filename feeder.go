@@ -0,0 +1,223 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"errors"
+	"io"
+	"unicode/utf8"
+)
+
+// ErrFeederClosed is returned by a Feeder that is fed after Close.
+var ErrFeederClosed = errors.New("feeder closed")
+
+// Feeder splits words from input that arrives in chunks of any size, such as
+// frames of a network protocol, following the same rules as a Lexer. Quotes,
+// escapes and words may span chunks; a word is returned once the input
+// following it shows that it is complete.
+//
+// A Feeder is not safe for concurrent use.
+type Feeder struct {
+	cfg config
+
+	// sc scans in, keeping the state of a word that reaches the end of
+	// the input from one chunk to the next.
+	sc *scanner
+	in feedReader
+
+	// err is returned by all calls once the Feeder has failed or been
+	// closed.
+	err error
+}
+
+// errNeedMore is returned by a feedReader that has run out of input before
+// it is closed.
+var errNeedMore = errors.New("need more input")
+
+// feedReader reads the input of a Feeder from buf, which holds the input
+// from the last point the scanner stopped at, which is off.
+type feedReader struct {
+	buf []byte
+	off int
+
+	// size is that of the last rune or byte read, for UnreadRune.
+	size int
+
+	// final is set once the input has ended, and short counts the reads
+	// that ran out of input before that.
+	final bool
+	short int
+}
+
+func (r *feedReader) ReadRune() (rune, int, error) {
+	r.size = 0
+	if err := r.more(1); err != nil {
+		return 0, 0, err
+	}
+	c, size := rune(r.buf[r.off]), 1
+	if c >= utf8.RuneSelf {
+		if !utf8.FullRune(r.buf[r.off:]) {
+			// The rest of the rune may be in the next chunk.
+			if err := r.more(len(r.buf) - r.off + 1); err != nil {
+				return 0, 0, err
+			}
+		}
+		c, size = utf8.DecodeRune(r.buf[r.off:])
+	}
+	r.off += size
+	r.size = size
+	return c, size, nil
+}
+
+// more returns an error if there are fewer than n bytes left.
+func (r *feedReader) more(n int) error {
+	switch {
+	case r.off+n <= len(r.buf):
+		return nil
+	case r.final:
+		if r.off < len(r.buf) {
+			// A truncated rune at the end.
+			return nil
+		}
+		return io.EOF
+	}
+	r.short++
+	return errNeedMore
+}
+
+func (r *feedReader) UnreadRune() error {
+	if r.size == 0 {
+		return errors.New("shlex: UnreadRune not after ReadRune")
+	}
+	r.off -= r.size
+	r.size = 0
+	return nil
+}
+
+func (r *feedReader) ReadByte() (byte, error) {
+	r.size = 0
+	if err := r.more(1); err != nil {
+		return 0, err
+	}
+	r.off++
+	r.size = 1
+	return r.buf[r.off-1], nil
+}
+
+func (r *feedReader) UnreadByte() error {
+	return r.UnreadRune()
+}
+
+// feedState is a saved state of the scanner of a Feeder, to go back to when
+// it had to decide about the end of a chunk without seeing the next one.
+type feedState struct {
+	sc  scanner
+	off int
+
+	// last is the last rune of the token, which dropDollar may have
+	// removed and overwritten since. nest is a copy of the scanner's.
+	last rune
+	nest []rune
+}
+
+func (f *Feeder) save() feedState {
+	st := feedState{sc: *f.sc, off: f.in.off, nest: append([]rune(nil), f.sc.nest...)}
+	if n := len(f.sc.token); n > 0 {
+		st.last = f.sc.token[n-1]
+	}
+	return st
+}
+
+func (f *Feeder) restore(st feedState) {
+	*f.sc = st.sc
+	if n := len(f.sc.token); n > 0 {
+		f.sc.token[n-1] = st.last
+	}
+	f.sc.nest = st.nest
+	f.in.off = st.off
+	f.in.size = 0
+}
+
+// NewFeeder returns a Feeder with no input.
+func NewFeeder(opts ...Option) *Feeder {
+	f := &Feeder{cfg: newConfig(opts)}
+	f.sc = newScanner(&f.in, &f.cfg)
+	return f
+}
+
+// Feed adds chunk to the input and returns the words it completes.
+//
+// If a limit is exceeded, Feed returns a *ParseError, as does every later
+// call.
+func (f *Feeder) Feed(chunk []byte) ([]string, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	// What is before off has been scanned for good.
+	f.in.buf = append(f.in.buf[:copy(f.in.buf, f.in.buf[f.in.off:])], chunk...)
+	f.in.off = 0
+	return f.scan(false)
+}
+
+// Close ends the input and returns the remaining words.
+//
+// If the input ends within quotes or with a trailing backslash, Close returns
+// the incomplete final word along with a *ParseError, as Lexer.Next does,
+// unless WithStrictErrors(false) is given. Once closed, the Feeder returns
+// ErrFeederClosed.
+func (f *Feeder) Close() ([]string, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	words, err := f.scan(true)
+	if err == nil {
+		f.err = ErrFeederClosed
+	}
+	return words, err
+}
+
+// scan splits the buffered input, continuing from where the last call
+// stopped. Unless final, the scanner stops at the end of the input within a
+// word, which may continue in the next chunk.
+func (f *Feeder) scan(final bool) ([]string, error) {
+	f.in.final = final
+	var words []string
+	for {
+		st := f.save()
+		f.in.short = 0
+		w, err := f.sc.next()
+		if f.in.short > 1 || f.in.short == 1 && err != errNeedMore {
+			// Looking ahead ran out of input, e.g. after the &
+			// that may begin &&, so the scanner may have decided
+			// wrongly. Scan again once more input has arrived.
+			f.restore(st)
+			return words, nil
+		}
+		switch err.(type) {
+		case nil:
+			words = append(words, w.value)
+			continue
+		case *BudgetError:
+			p := f.sc.errPos
+			p.Source = f.cfg.source
+			f.err = &ParseError{Pos: p, Err: err}
+			return words, f.err
+		}
+		switch {
+		case err == errNeedMore:
+			return words, nil
+		case err != io.EOF:
+			f.err = err
+			return words, err
+		}
+		if uerr := f.sc.unterminated(); uerr != nil && !f.cfg.lenient {
+			open := f.sc.open
+			open.Source = f.cfg.source
+			f.err = &ParseError{Pos: open, Err: uerr}
+			return words, f.err
+		}
+		return words, nil
+	}
+}
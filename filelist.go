@@ -0,0 +1,32 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"strings"
+)
+
+// EncodeFileList encodes a list of file names, one quoted name per line.
+//
+// Unlike a plain newline-separated list, names containing spaces, newlines
+// or quotes survive the round trip through DecodeFileList. A shell script
+// can read the list with
+//
+//	eval "set -- $(cat list)"
+func EncodeFileList(names []string) string {
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(Quote(name))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// DecodeFileList decodes a list of file names encoded by EncodeFileList. Any
+// whitespace may separate the names, so lists written by hand or by a shell
+// script using single or double quotes are accepted as well.
+func DecodeFileList(s string) []string {
+	return Split(s)
+}
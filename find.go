@@ -0,0 +1,37 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"path"
+)
+
+// FindArg returns the index of the first argument of argv that matches
+// pattern, or -1 if there is none. Patterns use the syntax of path.Match, so
+// "-o" matches only "-o" and "-o*" also matches "-ofile"; a malformed pattern
+// matches nothing.
+func FindArg(argv []string, pattern string) int {
+	for i, arg := range argv {
+		if ok, _ := path.Match(pattern, arg); ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// FindArgSpan is like FindArg for the words of line, matching pattern
+// against words with quotes and escapes removed. It also returns the byte
+// span [start, end) of the matching word, as written, in line, so that
+// editors can move the cursor to or replace it. If no word matches, all
+// results are -1.
+func FindArgSpan(line, pattern string, opts ...Option) (index, start, end int) {
+	tokens, _ := Lex(line, opts...)
+	for i, t := range tokens {
+		if ok, _ := path.Match(pattern, t.Value); ok {
+			return i, t.Pos.Offset, t.Pos.Offset + len(t.Raw)
+		}
+	}
+	return -1, -1, -1
+}
@@ -0,0 +1,174 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrMissingFlagValue is returned by MergeFlags when a flag that takes a
+// value is the last argument.
+var ErrMissingFlagValue = errors.New("flag needs a value")
+
+// FlagKind describes how MergeFlags merges repetitions of a flag.
+type FlagKind uint8
+
+const (
+	// FlagCount is a flag without a value, such as -v, whose repetitions
+	// are counted.
+	FlagCount FlagKind = iota
+
+	// FlagList is a flag with a value, such as --include, whose values
+	// are all collected.
+	FlagList
+
+	// FlagLast is a flag with a value, such as -o, of which only the last
+	// value is kept.
+	FlagLast
+)
+
+// Flags is the result of MergeFlags.
+type Flags struct {
+	// Counts holds the number of occurrences of each FlagCount flag.
+	Counts map[string]int
+
+	// Values holds the values of each FlagList and FlagLast flag, in
+	// order. For FlagLast, it holds only the last value.
+	Values map[string][]string
+
+	// Args holds the remaining arguments in order, including flags not in
+	// the spec, but not a "--" ending the flags.
+	Args []string
+}
+
+// MergeFlags merges repeated flags in args according to spec, which maps
+// flag names such as "-v" or "--include" to their kind. Pass argv[1:] to skip
+// the command name.
+//
+// Values are given as the next argument ("--include a", "-I a") or attached
+// to the flag ("--include=a", "-Ia"). Clusters of short flags such as "-vvx"
+// are taken apart if each of their flags is in spec. Arguments after "--"
+// are never flags.
+func MergeFlags(args []string, spec map[string]FlagKind) (*Flags, error) {
+	f := &Flags{
+		Counts: make(map[string]int),
+		Values: make(map[string][]string),
+	}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			f.Args = append(f.Args, args[i+1:]...)
+			break
+		}
+
+		// next returns the value of the flag name.
+		next := func(name string) (string, error) {
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("%s: %w", name, ErrMissingFlagValue)
+			}
+			i++
+			return args[i], nil
+		}
+
+		name, value, hasValue := arg, "", false
+		if j := strings.IndexByte(arg, '='); j >= 0 && strings.HasPrefix(arg, "--") {
+			name, value, hasValue = arg[:j], arg[j+1:], true
+		}
+		kind, ok := spec[name]
+		switch {
+		case ok && kind == FlagCount && !hasValue:
+			f.Counts[name]++
+
+		case ok && kind != FlagCount:
+			if !hasValue {
+				var err error
+				if value, err = next(name); err != nil {
+					return nil, err
+				}
+			}
+			f.add(name, kind, value)
+
+		case !ok && len(arg) > 2 && arg[0] == '-' && arg[1] != '-' && shortCluster(arg, spec):
+			for j := 1; j < len(arg); j++ {
+				name := "-" + arg[j:j+1]
+				if kind := spec[name]; kind == FlagCount {
+					f.Counts[name]++
+					continue
+				}
+				value := arg[j+1:]
+				if len(value) == 0 {
+					var err error
+					if value, err = next(name); err != nil {
+						return nil, err
+					}
+				}
+				f.add(name, spec[name], value)
+				break
+			}
+
+		default:
+			f.Args = append(f.Args, arg)
+		}
+	}
+	return f, nil
+}
+
+func (f *Flags) add(name string, kind FlagKind, value string) {
+	if kind == FlagLast {
+		f.Values[name] = []string{value}
+		return
+	}
+	f.Values[name] = append(f.Values[name], value)
+}
+
+// shortCluster reports whether arg, such as -vvx or -Ipath, consists of
+// short flags in spec, up to the first one that takes a value.
+func shortCluster(arg string, spec map[string]FlagKind) bool {
+	for j := 1; j < len(arg); j++ {
+		kind, ok := spec["-"+arg[j:j+1]]
+		if !ok {
+			return false
+		}
+		if kind != FlagCount {
+			return true
+		}
+	}
+	return true
+}
+
+// ExtractFlagValue splits line and returns the value of flag, such as
+// "--output" or "-o", as given by the last occurrence of the flag, which is
+// the one most programs use. It reports whether the flag was found with a
+// value.
+//
+// The value is the next word ("--output a"), or is attached to the flag
+// with = ("--output=a"). A flag of a single letter, such as -o, also takes an
+// attached value without = ("-oa"). Quoting is removed from the value, and
+// words after "--" are never flags.
+func ExtractFlagValue(line, flag string) (string, bool) {
+	short := len(flag) == 2 && flag[0] == '-' && flag[1] != '-'
+
+	words := Split(line)
+	value, found := "", false
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+		switch {
+		case w == "--":
+			return value, found
+		case w == flag:
+			if i+1 < len(words) {
+				i++
+				value, found = words[i], true
+			}
+		case short && strings.HasPrefix(w, flag):
+			value, found = w[len(flag):], true
+		case !short && strings.HasPrefix(w, flag+"="):
+			value, found = w[len(flag)+1:], true
+		}
+	}
+	return value, found
+}
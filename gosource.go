@@ -0,0 +1,53 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"strconv"
+	"strings"
+)
+
+// GoExecCommand returns Go source for an os/exec call running line, for
+// tools that migrate shell scripts to Go, e.g.
+//
+//	exec.Command("tar", "-xzf", "my file.tgz")
+//
+// for `tar -xzf 'my file.tgz'`. If line needs a shell, as reported by
+// RequiresShell, the call runs it with sh -c instead.
+//
+// Arguments are written as Go string literals: raw strings if they contain
+// quotes or backslashes and can be, and interpreted ones otherwise. It fails
+// as SplitErr does, or with ErrEmptyCommand if line has no words.
+func GoExecCommand(line string) (string, error) {
+	argv, err := SplitErr(line)
+	if err != nil {
+		return "", err
+	}
+	if len(argv) == 0 {
+		return "", ErrEmptyCommand
+	}
+	if RequiresShell(line) {
+		argv = []string{"sh", "-c", strings.TrimSpace(line)}
+	}
+
+	var b strings.Builder
+	b.WriteString("exec.Command(")
+	for i, arg := range argv {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(goLiteral(arg))
+	}
+	b.WriteByte(')')
+	return b.String(), nil
+}
+
+// goLiteral returns s as a Go string literal.
+func goLiteral(s string) string {
+	if strings.ContainsAny(s, `"\`) && strconv.CanBackquote(s) {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
@@ -0,0 +1,73 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrNUL is returned when a string to be passed to a shell contains a NUL
+// byte, which shells cannot represent.
+var ErrNUL = errors.New("string contains NUL byte")
+
+// Heredoc returns a shell command that runs argv with payload as its
+// standard input, given as a quoted here-document:
+//
+//	cat <<'EOF'
+//	payload
+//	EOF
+//
+// The payload is passed through unchanged, with no expansions. The delimiter
+// is EOF, or EOF_1, EOF_2 and so on if a line of payload is EOF. A newline is
+// added to payload unless it is empty or already ends with one.
+//
+// If payload contains a NUL byte, Heredoc returns ErrNUL.
+func Heredoc(argv []string, payload string) (string, error) {
+	if strings.IndexByte(payload, 0) >= 0 {
+		return "", ErrNUL
+	}
+	if len(payload) > 0 && !strings.HasSuffix(payload, "\n") {
+		payload += "\n"
+	}
+	delim := heredocDelimiter(payload)
+
+	var b strings.Builder
+	b.WriteString(Join(argv))
+	if len(argv) > 0 {
+		b.WriteByte(' ')
+	}
+	b.WriteString("<<'" + delim + "'\n")
+	b.WriteString(payload)
+	b.WriteString(delim + "\n")
+	return b.String(), nil
+}
+
+// HeredocDelimiter returns a delimiter for a here-document with body, for
+// callers writing their own: EOF, or EOF_1, EOF_2 and so on if a line of body
+// is EOF. quote reports whether body contains $, ` or \, which the shell
+// expands or removes unless the delimiter is quoted, as in <<'EOF'. Without
+// them, the delimiter may be left bare, so that expansions can be added to
+// the body later.
+func HeredocDelimiter(body string) (delim string, quote bool) {
+	return heredocDelimiter(body), strings.ContainsAny(body, "$`\\")
+}
+
+// heredocDelimiter returns a delimiter that does not occur as a line of
+// payload.
+func heredocDelimiter(payload string) string {
+	lines := make(map[string]struct{})
+	for _, line := range strings.Split(payload, "\n") {
+		lines[line] = struct{}{}
+	}
+	delim := "EOF"
+	for i := 1; ; i++ {
+		if _, ok := lines[delim]; !ok {
+			return delim
+		}
+		delim = "EOF_" + strconv.Itoa(i)
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HistoryEntry is a single command read from a shell history file.
+type HistoryEntry struct {
+	// Time is when the command was run. It is the zero Time if the
+	// history file does not record timestamps.
+	Time time.Time
+
+	// Duration is how long the command ran for. Only zsh records it.
+	Duration time.Duration
+
+	// Line is the command as it was typed. Multi-line commands contain
+	// newlines.
+	Line string
+
+	// Args is Line split into words by Split.
+	Args []string
+}
+
+func newHistoryEntry(t time.Time, d time.Duration, line string) HistoryEntry {
+	return HistoryEntry{
+		Time:     t,
+		Duration: d,
+		Line:     line,
+		Args:     Split(line),
+	}
+}
+
+// readLines calls fn for each line of r, without its line ending.
+func readLines(r io.Reader, fn func(n int, line string) error) error {
+	br := bufio.NewReader(r)
+	for n := 1; ; n++ {
+		line, err := br.ReadString('\n')
+		if len(line) > 0 {
+			line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+			if err := fn(n, line); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// parseUnix parses a decimal Unix timestamp in seconds.
+func parseUnix(s string) (time.Time, bool) {
+	if len(s) == 0 {
+		return time.Time{}, false
+	}
+	sec, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(sec, 0), true
+}
+
+// ParseBashHistory reads a Bash history file such as ~/.bash_history.
+//
+// When Bash saves timestamps (HISTTIMEFORMAT is set), each command is
+// preceded by a "#<seconds>" line, and all lines up to the next timestamp
+// belong to the same command. Otherwise, every non-empty line is a command.
+func ParseBashHistory(r io.Reader) ([]HistoryEntry, error) {
+	var entries []HistoryEntry
+	var ts time.Time
+	var lines []string
+	timed := false
+
+	flush := func() {
+		if len(lines) > 0 {
+			entries = append(entries, newHistoryEntry(ts, 0, strings.Join(lines, "\n")))
+		}
+		lines = nil
+	}
+
+	err := readLines(r, func(_ int, line string) error {
+		if strings.HasPrefix(line, "#") {
+			if t, ok := parseUnix(line[1:]); ok {
+				flush()
+				ts, timed = t, true
+				return nil
+			}
+		}
+		if len(line) == 0 {
+			return nil
+		}
+		lines = append(lines, line)
+		if !timed {
+			flush()
+		}
+		return nil
+	})
+	flush()
+	return entries, err
+}
+
+// unmetafy undoes zsh's encoding of history bytes, where a 0x83 byte marks
+// that the following byte has been XORed with 32.
+func unmetafy(s string) string {
+	if strings.IndexByte(s, 0x83) < 0 {
+		return s
+	}
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == 0x83 && i+1 < len(s) {
+			i++
+			b = append(b, s[i]^32)
+		} else {
+			b = append(b, s[i])
+		}
+	}
+	return string(b)
+}
+
+// ParseZshHistory reads a zsh history file such as ~/.zsh_history.
+//
+// Both the plain format and the extended format written with
+// EXTENDED_HISTORY (": <start>:<elapsed>;<command>") are accepted. A line
+// ending in a backslash continues the command on the next line.
+func ParseZshHistory(r io.Reader) ([]HistoryEntry, error) {
+	var entries []HistoryEntry
+	var ts time.Time
+	var dur time.Duration
+	var lines []string
+
+	err := readLines(r, func(n int, line string) error {
+		line = unmetafy(line)
+		if len(lines) == 0 {
+			ts, dur = time.Time{}, 0
+			if strings.HasPrefix(line, ": ") {
+				i := strings.IndexByte(line, ';')
+				if i < 0 {
+					return fmt.Errorf("line %d: missing ';' in extended history entry", n)
+				}
+				meta := strings.SplitN(line[2:i], ":", 2)
+				t, ok := parseUnix(meta[0])
+				if !ok {
+					return fmt.Errorf("line %d: invalid timestamp %q", n, meta[0])
+				}
+				ts = t
+				if len(meta) == 2 {
+					d, err := strconv.ParseInt(meta[1], 10, 64)
+					if err != nil {
+						return fmt.Errorf("line %d: invalid duration %q", n, meta[1])
+					}
+					dur = time.Duration(d) * time.Second
+				}
+				line = line[i+1:]
+			}
+		}
+
+		if strings.HasSuffix(line, `\`) {
+			lines = append(lines, strings.TrimSuffix(line, `\`))
+			return nil
+		}
+		lines = append(lines, line)
+		entry := strings.Join(lines, "\n")
+		lines = nil
+		if len(entry) > 0 {
+			entries = append(entries, newHistoryEntry(ts, dur, entry))
+		}
+		return nil
+	})
+	if err == nil && len(lines) > 0 {
+		entries = append(entries, newHistoryEntry(ts, dur, strings.Join(lines, "\n")))
+	}
+	return entries, err
+}
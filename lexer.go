@@ -0,0 +1,345 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// Position is a location in the input of a Lexer.
+type Position struct {
+	// Source is the name of the input, as set by WithSource.
+	Source string
+
+	// Offset is the byte offset, starting at 0.
+	Offset int
+
+	// Line is the line number, starting at 1.
+	Line int
+
+	// Column is the column number in characters, starting at 1.
+	Column int
+
+	// UTF16Offset and UTF16Column are the offset and column in UTF-16
+	// code units, as used by the Language Server Protocol and some
+	// editors. They are only set WithUTF16Positions.
+	UTF16Offset int
+	UTF16Column int
+}
+
+// String returns the position as "source:line:column", or "line:column" if
+// Source is empty.
+func (p Position) String() string {
+	if len(p.Source) == 0 {
+		return fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.Source, p.Line, p.Column)
+}
+
+// ParseError is returned by a Lexer for malformed input, recording where the
+// problem was found.
+type ParseError struct {
+	// Pos is the position of the offending construct, e.g. the opening
+	// quote of an unterminated string.
+	Pos Position
+
+	// Err is the underlying error, e.g. ErrUnterminatedSingleQuote.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Pos, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Lexer splits words from an io.Reader one at a time, following the same
+// rules as Split.
+//
+// A Lexer is not safe for concurrent use.
+type Lexer struct {
+	cfg config
+	sc  *scanner
+
+	// err is returned by all calls once the input is exhausted or has
+	// failed.
+	err error
+
+	// pos and end are the positions of the start and end of the word
+	// last returned by Next, and raw is its text as written.
+	pos Position
+	end Position
+	raw string
+
+	// rec records the input of a Lexer made by NewLexer for Raw. br
+	// reads through it, unless the input is an io.RuneScanner, which it
+	// wraps instead.
+	rec *recorder
+	br  *bufio.Reader
+
+	// pending receives the result of a read started by NextContext that
+	// was abandoned when its context was done.
+	pending chan result
+}
+
+type result struct {
+	word word
+	raw  string
+	err  error
+}
+
+// recorder keeps the input read by a Lexer from the end of the last word
+// on, which starts at offset off. It is written to by an io.TeeReader, or
+// records the runes read from in. Invalid bytes that it can get from in are
+// returned as by scanner.invalidByte.
+type recorder struct {
+	buf []byte
+	off int
+
+	in io.RuneScanner
+
+	// size is the size of the last rune read from in, and byteRead is set
+	// if it was read as a byte, to get an invalid byte as is.
+	size     int
+	byteRead bool
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	r.buf = append(r.buf, p...)
+	return len(p), nil
+}
+
+func (r *recorder) ReadRune() (rune, int, error) {
+	c, size, err := r.in.ReadRune()
+	if err != nil {
+		return c, size, err
+	}
+	r.size = size
+	r.byteRead = false
+	bs, ok := r.in.(io.ByteScanner)
+	if c == utf8.RuneError && size == 1 && ok && r.in.UnreadRune() == nil {
+		b, err := bs.ReadByte()
+		if err != nil {
+			return c, size, err
+		}
+		r.buf = append(r.buf, b)
+		r.byteRead = true
+		return invalidBase + rune(b), size, nil
+	}
+	var enc [utf8.UTFMax]byte
+	n := utf8.EncodeRune(enc[:], c)
+	if n != size {
+		// Not a ByteScanner: the invalid input is lost.
+		n = copy(enc[:], "\xff\xff\xff\xff"[:size])
+	}
+	r.buf = append(r.buf, enc[:n]...)
+	return c, size, nil
+}
+
+func (r *recorder) UnreadRune() error {
+	var err error
+	if r.byteRead {
+		err = r.in.(io.ByteScanner).UnreadByte()
+	} else {
+		err = r.in.UnreadRune()
+	}
+	if err == nil {
+		r.buf = r.buf[:len(r.buf)-r.size]
+		r.byteRead = false
+	}
+	return err
+}
+
+// take returns the input from start to end and discards it, along with the
+// input before it.
+func (r *recorder) take(start, end int) string {
+	s := string(r.buf[start-r.off : end-r.off])
+	r.buf = append(r.buf[:0], r.buf[end-r.off:]...)
+	r.off = end
+	return s
+}
+
+// NewLexer returns a Lexer reading from r.
+//
+// If r is an io.RuneScanner, such as a *bufio.Reader, the Lexer reads from it
+// directly rather than through a buffer of its own, and never reads further
+// than it must: after each word, r is positioned just past it, so that r can
+// be handed back and forth between the Lexer and other readers. Otherwise,
+// the Lexer may read ahead of the words it has returned.
+func NewLexer(r io.Reader, opts ...Option) *Lexer {
+	l := &Lexer{cfg: newConfig(opts)}
+	l.Reset(r)
+	return l
+}
+
+// Reset discards the state of the Lexer and makes it read from r, keeping its
+// options and reusing its buffers, so that Lexers can be pooled. The zero
+// Lexer can be Reset to be used with the default options.
+func (l *Lexer) Reset(r io.Reader) {
+	if l.pending != nil || l.rec == nil {
+		// A read abandoned by NextContext may still use the old
+		// buffers.
+		l.rec = &recorder{}
+		l.br = nil
+		l.sc = nil
+	}
+	l.rec.buf = l.rec.buf[:0]
+	l.rec.off = 0
+
+	var in io.RuneScanner
+	if rs, ok := r.(io.RuneScanner); ok {
+		l.rec.in = rs
+		in = l.rec
+	} else {
+		l.rec.in = nil
+		if l.br == nil {
+			l.br = bufio.NewReader(io.TeeReader(r, l.rec))
+		} else {
+			l.br.Reset(io.TeeReader(r, l.rec))
+		}
+		in = l.br
+	}
+	if l.sc == nil {
+		l.sc = newScanner(in, &l.cfg)
+	} else {
+		l.sc.reset(in)
+	}
+	l.sc.shared = l.rec.in != nil
+	l.err = nil
+	l.pos = Position{}
+	l.end = Position{}
+	l.raw = ""
+	l.pending = nil
+}
+
+func (l *Lexer) read() result {
+	if l.err != nil {
+		return result{err: l.err}
+	}
+	res := readWord(l.sc, l.rec, &l.cfg)
+	if res.err != nil {
+		l.err = res.err
+	}
+	return res
+}
+
+// readWord reads the next word with sc, taking its raw text from rec if it is
+// not nil. It does not touch the Lexer, so that NextContext can leave it
+// running after a Reset.
+func readWord(sc *scanner, rec *recorder, cfg *config) result {
+	w, err := sc.next()
+	raw := ""
+	switch err.(type) {
+	case nil:
+		w.pos.Source = cfg.source
+		w.endPos.Source = cfg.source
+		if rec != nil {
+			raw = rec.take(w.start, w.end)
+		}
+
+	case *BudgetError:
+		p := sc.errPos
+		p.Source = cfg.source
+		err = &ParseError{Pos: p, Err: err}
+
+	default:
+		if err == io.EOF {
+			if uerr := sc.unterminated(); uerr != nil && !cfg.lenient {
+				open := sc.open
+				open.Source = cfg.source
+				err = &ParseError{Pos: open, Err: uerr}
+			}
+		}
+	}
+	return result{word: w, raw: raw, err: err}
+}
+
+// Next returns the next word.
+//
+// At the end of the input, Next returns io.EOF. If the input ends within
+// quotes or with a trailing backslash, the incomplete final word is returned
+// first, followed by a *ParseError wrapping ErrUnterminatedSingleQuote,
+// ErrUnterminatedDoubleQuote or ErrTrailingBackslash instead of io.EOF.
+func (l *Lexer) Next() (string, error) {
+	var res result
+	if l.pending != nil {
+		res = <-l.pending
+		l.pending = nil
+	} else {
+		res = l.read()
+	}
+	l.update(res)
+	return res.word.value, res.err
+}
+
+func (l *Lexer) update(res result) {
+	if res.err != nil {
+		l.err = res.err
+	} else {
+		l.pos = res.word.pos
+		l.end = res.word.endPos
+		l.raw = res.raw
+	}
+}
+
+// Pos returns the position of the start of the word most recently returned by
+// Next or NextContext.
+func (l *Lexer) Pos() Position {
+	return l.pos
+}
+
+// End returns the position just past the end of the word most recently
+// returned by Next or NextContext. Together with Pos, it gives the line and
+// column range of the word, e.g. for underlining it in a diagnostic.
+func (l *Lexer) End() Position {
+	return l.end
+}
+
+// Raw returns the word most recently returned by Next or NextContext as
+// written in the input, with quotes and escapes intact, so that tools
+// rewriting the input can copy words they leave alone byte for byte.
+func (l *Lexer) Raw() string {
+	return l.raw
+}
+
+// NextContext is like Next, but returns ctx.Err() if ctx is done before the
+// next word has been read.
+//
+// A read that is blocked in the underlying io.Reader cannot be interrupted.
+// It continues in the background after NextContext returns, and its word is
+// returned by the next call to Next or NextContext, so no input is lost.
+func (l *Lexer) NextContext(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if l.pending == nil {
+		if l.err != nil {
+			return "", l.err
+		}
+		// The read may outlive a Reset, which replaces the scanner and
+		// recorder, so it gets the current ones rather than the Lexer.
+		ch := make(chan result, 1)
+		sc, rec, cfg := l.sc, l.rec, &l.cfg
+		go func() {
+			ch <- readWord(sc, rec, cfg)
+		}()
+		l.pending = ch
+	}
+
+	select {
+	case res := <-l.pending:
+		l.pending = nil
+		l.update(res)
+		return res.word.value, res.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
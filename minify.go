@@ -0,0 +1,224 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrQuotedNewline is returned by Minify for a newline within quotes,
+	// which cannot be written on one line.
+	ErrQuotedNewline = errors.New("newline within quotes")
+
+	// ErrHeredoc is returned by Minify for a here-document, whose body
+	// must be on lines of its own.
+	ErrHeredoc = errors.New("here-document")
+)
+
+// minifier tracks just enough shell grammar to tell whether a newline after
+// the last token ends a command.
+type minifier struct {
+	// cmd is set if the next token is in command position.
+	cmd bool
+
+	// caseWords counts down the words of "case word in", depth is the
+	// nesting of case statements, and pattern is set while a case
+	// pattern is expected.
+	caseWords int
+	depth     int
+	pattern   bool
+
+	// fn tracks a possible function definition: it is fnName after a word
+	// that may name one, fnParen after "name (", and fnKeyword after the
+	// reserved word function.
+	fn int
+
+	// joins is set if a newline after the last token needs no ;.
+	joins bool
+}
+
+const (
+	fnNone = iota
+	fnName
+	fnParen
+	fnKeyword
+)
+
+// token moves the minifier past the token t.
+func (m *minifier) token(t string, operator bool) {
+	m.joins = false
+	fn := m.fn
+	m.fn = fnNone
+	switch {
+	case m.pattern:
+		switch {
+		case t == "esac":
+			m.depth--
+			m.pattern = false
+			m.cmd = false
+		case t == ")" && operator:
+			m.pattern = false
+			m.cmd = true
+			m.joins = true
+		}
+
+	case m.caseWords > 0:
+		m.caseWords--
+		if m.caseWords == 0 && t == "in" {
+			m.depth++
+			m.pattern = true
+			m.joins = true
+		}
+
+	case operator:
+		m.cmd = t != ")"
+		m.joins = m.cmd
+		switch {
+		case t == ";;" && m.depth > 0:
+			m.pattern = true
+		case t == "(" && fn == fnName:
+			m.fn = fnParen
+		case t == ")" && fn == fnParen:
+			// The body of the function, such as { ... }, follows.
+			m.cmd = true
+			m.joins = true
+		}
+
+	case fn == fnKeyword:
+		// The name of "function name" may be followed by () or the
+		// body.
+		m.fn = fnName
+		m.cmd = true
+		m.joins = true
+
+	case m.cmd:
+		switch t {
+		case "case":
+			m.caseWords = 2
+			m.cmd = false
+		case "esac":
+			m.depth--
+			m.cmd = false
+		case "if", "elif", "then", "else", "while", "until", "do", "{", "!":
+			m.joins = true
+		case "function":
+			m.fn = fnKeyword
+			m.cmd = false
+		default:
+			m.fn = fnName
+			m.cmd = false
+		}
+	}
+}
+
+// Minify joins the logical lines of a small script into a one-liner, for
+// places that accept a single line only, such as sh -c arguments in some
+// configuration formats.
+//
+// Comments and blank lines are removed, line continuations are joined, and
+// the remaining lines are separated by "; ", or by a blank after operators
+// and reserved words such as | and then, which the command continues after.
+// Runs of blanks become one. Words are kept as written, so quoting and
+// expansions are unchanged.
+//
+// Minify fails with a *ParseError if the script ends within quotes, and with
+// ErrQuotedNewline or ErrHeredoc for what cannot be written on one line.
+func Minify(script string) (string, error) {
+	spans, err := Spans(removeContinuations(script), WithOperators(true))
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	m := minifier{cmd: true}
+	last := ""
+	for _, sp := range spans {
+		if !sp.Token {
+			switch {
+			case b.Len() == 0 || len(sp.Raw) == 0:
+			case strings.Contains(sp.Raw, "\n"):
+				last = "\n"
+			default:
+				last = " "
+			}
+			continue
+		}
+		if strings.Contains(sp.Raw, "\n") {
+			return "", ErrQuotedNewline
+		}
+
+		if last == "\n" {
+			if m.joins {
+				last = " "
+			} else {
+				b.WriteString(";")
+				last = " "
+				m.token(";", true)
+			}
+		}
+		if last == " " {
+			b.WriteByte(' ')
+		}
+		b.WriteString(sp.Raw)
+		last = ""
+
+		// With WithOperators, words never consist of operators alone.
+		_, operator := operators[sp.Raw]
+		if sp.Raw == "<<" || sp.Raw == "<<-" {
+			return "", ErrHeredoc
+		}
+		m.token(sp.Raw, operator)
+	}
+	return b.String(), nil
+}
+
+// removeContinuations removes backslash-newlines outside of single quotes
+// and comments, as the shell does.
+func removeContinuations(s string) string {
+	if !strings.Contains(s, "\\\n") {
+		return s
+	}
+	var b strings.Builder
+	inSingle, inDouble, inComment := false, false, false
+	// boundary is set at the start of s and after an unquoted blank, where
+	// a # begins a comment.
+	boundary := true
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		atStart := boundary
+		boundary = false
+		switch {
+		case inComment:
+			inComment = c != '\n'
+			boundary = !inComment
+		case inSingle:
+			inSingle = c != '\''
+		case c == '\\' && i+1 < len(s):
+			if s[i+1] == '\n' {
+				// The continuation is removed, so the next byte
+				// starts a word if this one would have.
+				boundary = atStart
+				i++
+				continue
+			}
+			b.WriteByte(c)
+			i++
+			c = s[i]
+		case c == '"':
+			inDouble = !inDouble
+		case inDouble:
+		case c == '\'':
+			inSingle = true
+		case c == '#' && atStart:
+			inComment = true
+		case isShellBlank(c):
+			boundary = true
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,48 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"strings"
+)
+
+// SplitMore is like Split, but when line ends within quotes or with a
+// trailing backslash, it calls more for the next line of input and continues
+// splitting, as an interactive shell shows its continuation prompt.
+//
+// Within quotes, the lines are joined with a newline. After a trailing
+// backslash, the backslash is removed and the lines are joined directly, as
+// a line continuation.
+//
+// If more returns an error, such as io.EOF, SplitMore returns the words of
+// the incomplete input along with that error.
+func SplitMore(line string, more func() (string, error), opts ...Option) ([]string, error) {
+	for {
+		words, err := splitWords(line, opts)
+		switch err {
+		case ErrTrailingBackslash, ErrUnterminatedSingleQuote, ErrUnterminatedDoubleQuote:
+		default:
+			return values(words), err
+		}
+
+		next, merr := more()
+		if merr != nil {
+			return values(words), merr
+		}
+		if err == ErrTrailingBackslash {
+			line = strings.TrimSuffix(line, `\`) + next
+		} else {
+			line += "\n" + next
+		}
+	}
+}
+
+func values(words []word) []string {
+	ret := make([]string, 0, len(words))
+	for _, w := range words {
+		ret = append(ret, w.value)
+	}
+	return ret
+}
@@ -0,0 +1,89 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+// The functions in this file implement word motions for line editors. Words
+// are shell words, so that 'a b' or a\ b count as a single word. Cursors and
+// returned positions are byte offsets into the line.
+
+// PrevWordStart returns the start of the word containing cursor, or of the
+// word before it if cursor is at or before the start of a word. It returns 0
+// if there is no such word.
+func PrevWordStart(line string, cursor int) int {
+	words, _ := splitWords(line, nil)
+	start := 0
+	for _, w := range words {
+		if w.start >= cursor {
+			break
+		}
+		start = w.start
+	}
+	return start
+}
+
+// NextWordEnd returns the end of the word containing cursor, or of the word
+// after it if cursor is not within a word. It returns len(line) if there is
+// no such word.
+func NextWordEnd(line string, cursor int) int {
+	words, _ := splitWords(line, nil)
+	for _, w := range words {
+		if w.end > cursor {
+			return w.end
+		}
+	}
+	return len(line)
+}
+
+// DeleteWordBackward deletes from the start of the word before cursor up to
+// cursor, as by Ctrl-W in a shell, returning the new line and cursor.
+func DeleteWordBackward(line string, cursor int) (string, int) {
+	start := PrevWordStart(line, cursor)
+	return line[:start] + line[cursor:], start
+}
+
+// TransposeWords swaps the word before cursor with the word at or after it,
+// as by M-t in a shell, returning the new line and a cursor placed after both
+// words. At the end of the line, the last two words are swapped. The text
+// between the words is kept as is.
+//
+// If there are fewer than two words, the line and cursor are returned
+// unchanged.
+func TransposeWords(line string, cursor int) (string, int) {
+	words, _ := splitWords(line, nil)
+	if len(words) < 2 {
+		return line, cursor
+	}
+
+	j := len(words) - 1
+	for i, w := range words {
+		if w.end > cursor {
+			j = i
+			break
+		}
+	}
+	if j == 0 {
+		j = 1
+	}
+	a, b := words[j-1], words[j]
+
+	s := line[:a.start] + line[b.start:b.end] + line[a.end:b.start] + line[a.start:a.end] + line[b.end:]
+	return s, b.end
+}
+
+// WordAt returns the word containing cursor, with quotes and escapes removed,
+// and its byte span [start, end) in line, for completion engines. A cursor
+// at the start or end of a word is within it, and a word left open in quotes
+// at the end of the line counts, so that "ls 'My Doc" completes My Doc. If
+// cursor is between words, WordAt returns an empty word at cursor, where a
+// new one would be inserted.
+func WordAt(line string, cursor int) (word string, start, end int) {
+	words, _ := splitWords(line, nil)
+	for _, w := range words {
+		if w.start <= cursor && cursor <= w.end {
+			return w.value, w.start, w.end
+		}
+	}
+	return "", cursor, cursor
+}
@@ -0,0 +1,195 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Rewrite is a single simplification made by NormalizeEscapes.
+type Rewrite struct {
+	// Offset is the byte offset of Old in the original line.
+	Offset int
+
+	// Old is the original text, e.g. `\e`.
+	Old string
+
+	// New is its replacement, e.g. "e".
+	New string
+}
+
+// needsEscape reports whether an unquoted backslash before r may change the
+// meaning of a line, either for Split or for a shell.
+func needsEscape(r rune) bool {
+	switch {
+	case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+		return false
+	case r >= utf8.RuneSelf:
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}
+	return !strings.ContainsRune("@%+:./-_", r)
+}
+
+// NormalizeEscapes removes quoting from line that has no effect, returning
+// the simplified line along with each simplification made. The words of the
+// result are the same as the words of line, under Split as well as in a
+// shell. Words in command position are left alone, since quoting them
+// suppresses alias lookup and reserved words: \ls is not ls, and i\f is
+// not if.
+//
+// Two simplifications are made: unquoted backslashes before ordinary
+// characters are removed (\e becomes e), and empty quotes within a word are
+// removed (a""b becomes ab). Backslashes within single or double quotes are
+// never removed, as they are literal there: '\"' is a backslash followed by
+// a double quote. Neither is removed where the shell would then expand the
+// word differently: after a $, within a tilde prefix, or within the name of
+// a variable assignment, as in $""x, ~""/x or FOO""=1. Quotes left open at
+// the end of line are kept as they are.
+func NormalizeEscapes(line string) (string, []Rewrite) {
+	var b strings.Builder
+	var rewrites []Rewrite
+
+	// inWord is set while b ends in a word that has content, which starts
+	// at wordStart in b. inCmd is set if that word is in command position,
+	// and cmd if the next word is.
+	inWord := false
+	wordStart := 0
+	inCmd, cmd := false, true
+	for i := 0; i < len(line); {
+		if !inWord {
+			wordStart = b.Len()
+			inCmd = cmd
+		}
+		r, size := utf8.DecodeRuneInString(line[i:])
+		switch {
+		case r == '\\':
+			if i+1 >= len(line) {
+				b.WriteString(line[i:])
+				i = len(line)
+				continue
+			}
+			next, nsize := utf8.DecodeRuneInString(line[i+1:])
+			old := line[i : i+1+nsize]
+			if needsEscape(next) || inCmd || quotingMatters(b.String()[wordStart:], line[i+1:]) {
+				b.WriteString(old)
+			} else {
+				b.WriteString(old[1:])
+				rewrites = append(rewrites, Rewrite{Offset: i, Old: old, New: old[1:]})
+			}
+			inWord = true
+			i += 1 + nsize
+
+		case r == '\'' || r == '"':
+			end := closingQuote(line, i)
+			if end >= len(line) {
+				b.WriteString(line[i:])
+				i = len(line)
+				continue
+			}
+			if end == i+1 && inWord && !inCmd && !quotingMatters(b.String()[wordStart:], line[end+1:]) {
+				rewrites = append(rewrites, Rewrite{Offset: i, Old: line[i : i+2]})
+			} else {
+				b.WriteString(line[i : end+1])
+				inWord = true
+			}
+			i = end + 1
+
+		case r == '#' && !inWord:
+			// The rest of the line is a comment.
+			end := indexByteFrom(line, i, '\n')
+			if end < 0 {
+				end = len(line)
+			}
+			b.WriteString(line[i:end])
+			i = end
+
+		default:
+			if unicode.IsSpace(r) {
+				if inWord {
+					cmd = inCmd && startsCommandAfter(b.String()[wordStart:])
+				}
+				cmd = cmd || r == '\n'
+			} else if strings.ContainsRune(";&|(", r) {
+				// The rest of the word is a command for the shell.
+				inCmd = true
+			}
+			inWord = !unicode.IsSpace(r)
+			b.WriteString(line[i : i+size])
+			i += size
+		}
+	}
+	return b.String(), rewrites
+}
+
+// commandPrefixes are the reserved words that a command may follow.
+var commandPrefixes = map[string]struct{}{
+	"!": {}, "{": {}, "do": {}, "elif": {}, "else": {}, "if": {}, "then": {},
+	"time": {}, "until": {}, "while": {},
+}
+
+// startsCommandAfter reports whether the word after word, which is in
+// command position, is in command position too: after a variable
+// assignment, a reserved word such as then, or an operator.
+func startsCommandAfter(word string) bool {
+	if _, ok := commandPrefixes[word]; ok {
+		return true
+	}
+	if strings.ContainsAny(word[len(word)-1:], ";&|(") {
+		return true
+	}
+	i := strings.IndexByte(word, '=')
+	return i > 0 && isName(strings.TrimSuffix(word[:i], "+"))
+}
+
+// quotingMatters reports whether quoting between word, the start of a word
+// as written so far, and rest, the line after the quoting, changes how the
+// shell expands the word.
+func quotingMatters(word, rest string) bool {
+	if strings.HasSuffix(word, "$") {
+		// $"..." and $'...' are quotes of their own, and $\x is not $x.
+		return true
+	}
+	if i := strings.LastIndexByte(word, '~'); i >= 0 && !strings.Contains(word[i:], "/") {
+		return true
+	}
+
+	// Find the name that word and rest make up, skipping other quoting,
+	// and whether it is assigned to.
+	name := word
+	for len(rest) > 0 {
+		switch {
+		case isNameChar(rest[0]):
+			name += rest[:1]
+			rest = rest[1:]
+		case len(rest) > 1 && rest[0] == '\\' && isNameChar(rest[1]):
+			name += rest[1:2]
+			rest = rest[2:]
+		case strings.HasPrefix(rest, "''") || strings.HasPrefix(rest, `""`):
+			rest = rest[2:]
+		default:
+			return isName(name) && (rest[0] == '=' || strings.HasPrefix(rest, "+="))
+		}
+	}
+	return false
+}
+
+// closingQuote returns the index of the quote closing the one at s[i], or
+// len(s) if there is none.
+func closingQuote(s string, i int) int {
+	q := s[i]
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			if q == '"' {
+				j++
+			}
+		case q:
+			return j
+		}
+	}
+	return len(s)
+}
@@ -0,0 +1,271 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"context"
+)
+
+// An Option configures how a line is split.
+type Option interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (f optionFunc) apply(c *config) {
+	f(c)
+}
+
+// config holds the settings of all options. The zero value is the default
+// behavior of Split.
+type config struct {
+	stripCR   bool
+	operators bool
+
+	// terminators are the runes set by WithTerminators.
+	terminators string
+
+	// continuation is the handling of backslash-newline within double
+	// quotes, and rawDoubleQuotes disables escapes within them entirely.
+	continuation    Continuation
+	rawDoubleQuotes bool
+
+	// apostrophes makes single quotes between letters literal.
+	apostrophes bool
+
+	// comments is the handling of comments, ifs the runes set by WithIFS
+	// and lenient is set by WithStrictErrors(false).
+	comments CommentMode
+	ifs      string
+	lenient  bool
+
+	// source names the input in positions, and utf16 adds UTF-16 offsets
+	// to them.
+	source string
+	utf16  bool
+
+	// decodeANSIC decodes the escapes of $'...', as set by
+	// WithANSICQuoting.
+	decodeANSIC bool
+
+	// posix follows POSIX sh rather than Bash, as in the POSIX profile:
+	// $'...' and $"..." are not quotes, an unquoted backslash-newline is
+	// removed, and WithOperators leaves out Bash's operators.
+	posix bool
+
+	// ctx is the context set by WithContext.
+	ctx context.Context
+
+	// maxBytes and maxTokens limit the input consumed and words
+	// produced, and maxTokenLen the input bytes of a word. Zero means
+	// unlimited.
+	maxBytes    int
+	maxTokens   int
+	maxTokenLen int
+}
+
+func newConfig(opts []Option) config {
+	var c config
+	for _, o := range opts {
+		o.apply(&c)
+	}
+	return c
+}
+
+// WithStripCR discards a carriage return immediately preceding a newline or
+// the end of input, so that lines with Windows (CRLF) line endings split the
+// same as their Unix counterparts. Without it, a CR that is escaped or quoted
+// ends up at the end of the last argument of the line.
+func WithStripCR(strip bool) Option {
+	return optionFunc(func(c *config) {
+		c.stripCR = strip
+	})
+}
+
+// WithSource names the input of a Lexer, e.g. with its file name, for use in
+// the Source of positions and errors.
+func WithSource(name string) Option {
+	return optionFunc(func(c *config) {
+		c.source = name
+	})
+}
+
+// WithUTF16Positions sets the UTF16Offset and UTF16Column of positions in
+// addition to their byte offset and column.
+func WithUTF16Positions(enable bool) Option {
+	return optionFunc(func(c *config) {
+		c.utf16 = enable
+	})
+}
+
+// WithOperators splits unquoted shell operators, such as |, &&, ; and >>, into
+// words of their own, even when they are not surrounded by blanks. Tokens of
+// operators have Operator set.
+//
+// Command substitutions and parameter expansions, such as $(a | b), ${x} and
+// `a; b`, are kept within their word, blanks and operators included.
+func WithOperators(split bool) Option {
+	return optionFunc(func(c *config) {
+		c.operators = split
+	})
+}
+
+// Continuation selects what becomes of a backslash-newline within double
+// quotes.
+type Continuation uint8
+
+const (
+	// ContinuationKeepNewline removes the backslash but keeps the newline.
+	// This is the default.
+	ContinuationKeepNewline Continuation = iota
+
+	// ContinuationRemove removes both, joining the lines as Bash and POSIX
+	// sh do.
+	ContinuationRemove
+
+	// ContinuationLiteral keeps both, as some embedded shells do.
+	ContinuationLiteral
+)
+
+// WithQuotedContinuation sets the handling of a backslash-newline within
+// double quotes. Unquoted backslash-newlines are not affected.
+func WithQuotedContinuation(c Continuation) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.continuation = c
+	})
+}
+
+// WithRawDoubleQuotes makes backslashes within double quotes literal, as in
+// some configuration file formats: "a\b" is a\b, and a double quote always
+// ends the quoted string. Backslashes outside of quotes still escape the
+// next character.
+func WithRawDoubleQuotes(raw bool) Option {
+	return optionFunc(func(c *config) {
+		c.rawDoubleQuotes = raw
+	})
+}
+
+// WithTerminators makes each of the given runes, when unquoted, end the word
+// before it and form a word of its own, like an operator of WithOperators.
+// This lets small languages that borrow shell quoting split, e.g., key=value
+// lists: with WithTerminators(",="), a='b c',d is split into a, =, b c, ",",
+// and d.
+func WithTerminators(runes string) Option {
+	return optionFunc(func(c *config) {
+		c.terminators = runes
+	})
+}
+
+// WithApostrophes makes an unquoted single quote between two letters, as in
+// "doesn't", a literal apostrophe rather than the start of a quoted string.
+// This is a heuristic for natural-language input such as chat commands:
+// "don't stop" is split into don't and stop, where by default the quote
+// would run to the end of the line. Quotes elsewhere, e.g. at the start of
+// a word, still quote.
+func WithApostrophes(literal bool) Option {
+	return optionFunc(func(c *config) {
+		c.apostrophes = literal
+	})
+}
+
+// CommentMode selects what becomes of a comment, which starts with a # at the
+// start of a word and runs to the end of the line.
+type CommentMode uint8
+
+const (
+	// CommentStrip removes comments. This is the default.
+	CommentStrip CommentMode = iota
+
+	// CommentLiteral makes # an ordinary character, as in many
+	// configuration formats: a #b is split into a and #b.
+	CommentLiteral
+
+	// CommentKeep returns each comment as a word of its own, from the #
+	// to the end of the line, so that it can be shown or preserved. Tokens
+	// of comments have Kind TokenComment.
+	CommentKeep
+)
+
+// WithComments sets the handling of comments.
+func WithComments(m CommentMode) Option {
+	return optionFunc(func(c *config) {
+		c.comments = m
+	})
+}
+
+// WithIFS makes the runes of ifs, when unquoted, separate words instead of
+// white space, as the shell's IFS variable does for the results of
+// expansions: with WithIFS(":"), 'a b':c is split into "a b" and c, and
+// blanks are ordinary characters. Unlike the shell, a run of separators
+// never delimits an empty word. The newline ending a comment separates words
+// regardless. An empty ifs restores the default.
+func WithIFS(ifs string) Option {
+	return optionFunc(func(c *config) {
+		c.ifs = ifs
+	})
+}
+
+// WithStrictErrors sets whether a Lexer reports input ending within quotes
+// or with a trailing backslash as a *ParseError, after returning the
+// incomplete final word. This is the default for a Lexer and for Lex, Spans
+// and SplitErr. With WithStrictErrors(false), they are lenient like Split:
+// open quotes are taken to be closed, a trailing backslash is dropped, and
+// the input ends with io.EOF. Budget and read errors are always reported.
+func WithStrictErrors(strict bool) Option {
+	return optionFunc(func(c *config) {
+		c.lenient = !strict
+	})
+}
+
+// WithContext makes splitting stop with ctx.Err() as soon as ctx is done,
+// even within a word, so that servers lexing untrusted and possibly
+// unbounded input can give up on a deadline or when the client goes away.
+// Functions returning an error, such as Lexer.Next and Lex, return it;
+// Split and others that do not return the words so far.
+//
+// As with Lexer.NextContext, a read that is blocked in the underlying
+// io.Reader cannot be interrupted.
+func WithContext(ctx context.Context) Option {
+	return optionFunc(func(c *config) {
+		c.ctx = ctx
+	})
+}
+
+// WithMaxTokens makes splitting fail once it would produce more than n words,
+// as a guard against hostile input. A zero n means no limit.
+//
+// Lexer.Next, Lex and SplitErr return a *ParseError wrapping a *BudgetError
+// with the TokenLimit; Split and others that do not return an error stop at
+// the limit, so callers that must not act on a partial result should use
+// SplitErr.
+func WithMaxTokens(n int) Option {
+	return optionFunc(func(c *config) {
+		c.maxTokens = n
+	})
+}
+
+// WithMaxTokenLen is like WithMaxTokens, but limits each word to n bytes of
+// input, quotes and escapes included, with the TokenLenLimit. No more than n
+// bytes of a word are buffered before it fails.
+func WithMaxTokenLen(n int) Option {
+	return optionFunc(func(c *config) {
+		c.maxTokenLen = n
+	})
+}
+
+// WithANSICQuoting makes splitting decode the escape sequences of Bash's
+// $'...' quoting, such as \n, \t, \xHH, \uHHHH and octal \nnn, into the words,
+// as in $'a\tb', which is split into a, a tab and b. Bytes given by \xHH and
+// octal escapes are kept as is, even if they are not valid UTF-8.
+//
+// By default, the quotes of a non-empty $'...' are removed like those of
+// '...', keeping the $ and the backslashes. The Bash profile enables
+// decoding, and the POSIX profile does not take $'...' as quotes at all.
+func WithANSICQuoting(decode bool) Option {
+	return optionFunc(func(c *config) {
+		c.decodeANSIC = decode
+	})
+}
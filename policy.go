@@ -0,0 +1,153 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrCommandNotAllowed is returned when a command is not listed in a
+	// Policy.
+	ErrCommandNotAllowed = errors.New("command not allowed")
+
+	// ErrFlagNotAllowed is returned when a flag is not listed for its
+	// command in a Policy.
+	ErrFlagNotAllowed = errors.New("flag not allowed")
+
+	// ErrExpansionNotAllowed is returned when a line contains an expansion
+	// that its Policy does not permit.
+	ErrExpansionNotAllowed = errors.New("expansion not allowed")
+
+	// ErrOperatorNotAllowed is returned when a line contains a shell
+	// operator that its Policy does not permit.
+	ErrOperatorNotAllowed = errors.New("operator not allowed")
+)
+
+// Policy is the set of commands that ValidateAgainstPolicy accepts.
+type Policy struct {
+	// Commands maps the allowed command names to their allowed flags.
+	// Names are compared exactly, so "/bin/ls" and "ls" are different
+	// commands. A nil list of flags allows any flags.
+	//
+	// A flag is an argument starting with "-", up to any "="; arguments
+	// after "--" are not flags. A cluster of short flags such as "-la" is
+	// allowed if it is listed itself, or if each of "-l" and "-a" is.
+	Commands map[string][]string
+
+	// AllowExpansions permits parameter and arithmetic expansion, command
+	// substitution, globs, and brace and tilde expansion.
+	AllowExpansions bool
+
+	// AllowOperators permits shell operators, such as pipes, lists and
+	// redirections. The command of each part of a pipeline or list is
+	// checked against Commands.
+	AllowOperators bool
+}
+
+// PolicyError describes the word of a line that violates a Policy.
+type PolicyError struct {
+	// Offset is the byte offset of the word in the line.
+	Offset int
+
+	// Word is the word as written.
+	Word string
+
+	// Err is one of ErrCommandNotAllowed, ErrFlagNotAllowed,
+	// ErrExpansionNotAllowed or ErrOperatorNotAllowed.
+	Err error
+}
+
+func (e *PolicyError) Error() string {
+	return fmt.Sprintf("offset %d: %s: %v", e.Offset, e.Word, e.Err)
+}
+
+func (e *PolicyError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateAgainstPolicy checks that line runs only commands and flags allowed
+// by policy, using only the shell features it permits, so that it can be
+// safely forwarded to a shell. Empty lines are valid. Words are split with
+// $'...' decoded, as Bash does, and expansions are only looked for within
+// them, not in comments.
+//
+// The returned error is a *PolicyError, or ErrUnterminatedSingleQuote,
+// ErrUnterminatedDoubleQuote or ErrTrailingBackslash for malformed lines.
+func ValidateAgainstPolicy(line string, policy Policy) error {
+	words, err := splitWords(line, []Option{WithOperators(true), WithANSICQuoting(true)})
+	if err != nil {
+		return err
+	}
+	if !policy.AllowExpansions {
+		exps, err := wordExpansions(line, words)
+		if err != nil {
+			return err
+		}
+		if len(exps) > 0 {
+			e := exps[0]
+			return &PolicyError{Offset: e.start, Word: line[e.start:e.end], Err: ErrExpansionNotAllowed}
+		}
+	}
+
+	var flags []string
+	command, anyFlags, flagsDone := true, false, false
+	for _, w := range words {
+		fail := func(err error) error {
+			return &PolicyError{Offset: w.start, Word: line[w.start:w.end], Err: err}
+		}
+		switch {
+		case w.operator:
+			if !policy.AllowOperators {
+				return fail(ErrOperatorNotAllowed)
+			}
+			command = startsCommand(Token{Raw: w.value, Operator: true})
+
+		case !policy.AllowExpansions && (w.glob || w.brace || w.tilde):
+			return fail(ErrExpansionNotAllowed)
+
+		case command:
+			var ok bool
+			flags, ok = policy.Commands[w.value]
+			if !ok {
+				return fail(ErrCommandNotAllowed)
+			}
+			command, anyFlags, flagsDone = false, flags == nil, false
+
+		case flagsDone || anyFlags || !strings.HasPrefix(w.value, "-") || w.value == "-":
+
+		case w.value == "--":
+			flagsDone = true
+
+		default:
+			if !flagAllowed(w.value, flags) {
+				return fail(ErrFlagNotAllowed)
+			}
+		}
+	}
+	return nil
+}
+
+// flagAllowed reports whether arg, which starts with "-", is allowed by
+// flags.
+func flagAllowed(arg string, flags []string) bool {
+	if i := strings.IndexByte(arg, '='); i >= 0 {
+		arg = arg[:i]
+	}
+	if contains(flags, arg) {
+		return true
+	}
+	if strings.HasPrefix(arg, "--") || len(arg) <= 2 {
+		return false
+	}
+	for _, c := range arg[1:] {
+		if !contains(flags, "-"+string(c)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,191 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Diagnostic describes a construct in a line that does not work in every
+// shell.
+type Diagnostic struct {
+	// Offset is the byte offset of the construct in the line.
+	Offset int
+
+	// Text is the text of the construct, e.g. "&>".
+	Text string
+
+	// Message explains the problem.
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("offset %d: %s: %s", d.Offset, d.Text, d.Message)
+}
+
+// CheckPortability reports the Bash extensions in line that a strict POSIX
+// shell, such as /bin/sh on many systems, does not support: $'...' and $"..."
+// quoting, process substitution, the &> and <<< redirections, [[ tests,
+// array assignments and subscripts, and brace expansion.
+//
+// As with ValidateTemplate, nothing within single quotes, comments, or after
+// a backslash is reported. Diagnostics are returned in order of appearance.
+func CheckPortability(line string) []Diagnostic {
+	var diags []Diagnostic
+	report := func(start, end int, msg string) {
+		diags = append(diags, Diagnostic{Offset: start, Text: line[start:end], Message: msg})
+	}
+
+	inDouble := false
+	// boundary is set at the start of line and after an unquoted blank,
+	// where a word begins. Escaped and quoted blanks do not count.
+	boundary := true
+	for i := 0; i < len(line); {
+		c := line[i]
+		rest := line[i:]
+		atStart := boundary
+		boundary = false
+		switch {
+		case c == '\\':
+			i += 2
+			continue
+
+		case c == '"':
+			inDouble = !inDouble
+
+		case strings.HasPrefix(rest, "${"):
+			// ${name[...]} subscripts an array.
+			n := 2
+			for n < len(rest) && isNameChar(rest[n]) {
+				n++
+			}
+			if n > 2 && n < len(rest) && rest[n] == '[' {
+				end := len(line)
+				if j, err := scanWord(line, i+2, '}', inDouble, nil); err == nil {
+					end = j + 1
+				}
+				report(i, end, "arrays are not POSIX")
+				i = end
+				continue
+			}
+
+		case inDouble:
+
+		case c == '\'':
+			end := indexByteFrom(line, i+1, '\'')
+			if end < 0 {
+				return diags
+			}
+			i = end + 1
+			continue
+
+		case c == '#' && atStart:
+			end := indexByteFrom(line, i, '\n')
+			if end < 0 {
+				return diags
+			}
+			i = end
+			continue
+
+		case strings.HasPrefix(rest, "$'"):
+			end := len(line)
+			for j := i + 2; j < len(line); j++ {
+				if line[j] == '\\' {
+					j++
+				} else if line[j] == '\'' {
+					end = j + 1
+					break
+				}
+			}
+			report(i, end, "ANSI-C quoting is not POSIX")
+			i = end
+			continue
+
+		case strings.HasPrefix(rest, `$"`):
+			report(i, i+2, "locale translation is not POSIX")
+			inDouble = true
+			i += 2
+			continue
+
+		case strings.HasPrefix(rest, "<(") || strings.HasPrefix(rest, ">("):
+			end := len(line)
+			if j, err := scanWord(line, i+2, ')', false, nil); err == nil {
+				end = j + 1
+			}
+			report(i, end, "process substitution is not POSIX")
+			i = end
+			continue
+
+		case strings.HasPrefix(rest, "&>"):
+			n := 2
+			if strings.HasPrefix(rest, "&>>") {
+				n = 3
+			}
+			report(i, i+n, "redirecting stdout and stderr with &> is not POSIX; use >file 2>&1")
+			i += n
+			continue
+
+		case strings.HasPrefix(rest, "<<<"):
+			report(i, i+3, "here-strings are not POSIX")
+			i += 3
+			continue
+
+		case strings.HasPrefix(rest, "[[") && atStart:
+			report(i, i+2, "[[ is not POSIX; use [ or test")
+
+		case isNameStart(c) && atStart:
+			n := 1
+			for n < len(rest) && isNameChar(rest[n]) {
+				n++
+			}
+			if strings.HasPrefix(rest[n:], "=(") {
+				report(i, i+n+2, "arrays are not POSIX")
+			}
+			i += n
+			continue
+
+		case c == '{':
+			if end, ok := braceExpansion(line, i); ok {
+				report(i, end, "brace expansion is not POSIX")
+				i = end
+				continue
+			}
+
+		case isShellBlank(c):
+			boundary = true
+		}
+		i++
+	}
+	return diags
+}
+
+// braceExpansion reports whether the unquoted { at s[i] begins a brace
+// expansion such as {a,b} or {1..3} within one word, returning the index
+// just past the closing brace.
+func braceExpansion(s string, i int) (int, bool) {
+	depth := 0
+	list := false
+	for j := i; j < len(s); j++ {
+		switch c := s[j]; c {
+		case '\\':
+			j++
+		case '\'', '"', '$', '`', ' ', '\t', '\n', ';', '|', '&', '<', '>', '(', ')':
+			return 0, false
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return j + 1, list
+			}
+		case ',':
+			list = true
+		case '.':
+			list = list || strings.HasPrefix(s[j:], "..")
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,47 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"strings"
+	"unicode"
+)
+
+// HasPrefixUnquoted reports whether the words of line begin with the words
+// of prefix, comparing words after quotes and escapes have been removed.
+//
+// For example, `"ls" -la` and `l\s -la` both have the prefix "ls", and
+// `git 'commit' -m x` has the prefix "git commit". `lsblk` does not have the
+// prefix "ls".
+func HasPrefixUnquoted(line, prefix string) bool {
+	words := Split(line)
+	for i, p := range Split(prefix) {
+		if i >= len(words) || words[i] != p {
+			return false
+		}
+	}
+	return true
+}
+
+// TrimPrefixWord returns line without its first word, if the first word
+// equals word once quotes and escapes have been removed. The remainder is
+// returned as it appears in line, with leading whitespace trimmed.
+//
+// If the first word of line is not word, TrimPrefixWord returns line and
+// false.
+func TrimPrefixWord(line string, word string) (string, bool) {
+	words, _ := splitWords(line, nil)
+	if len(words) == 0 || words[0].value != word {
+		return line, false
+	}
+	return strings.TrimLeftFunc(line[words[0].end:], unicode.IsSpace), true
+}
+
+// SplitIndent is like Split, but also returns the leading whitespace of line,
+// so that the indentation of a line can be reproduced along with its words.
+func SplitIndent(line string, opts ...Option) (indent string, args []string) {
+	rest := strings.TrimLeftFunc(line, unicode.IsSpace)
+	return line[:len(line)-len(rest)], Split(rest, opts...)
+}
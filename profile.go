@@ -0,0 +1,47 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+// A Profile is a named, immutable set of options, which can be passed as an
+// Option to any function taking them. Since it is resolved once, by
+// NewProfile, using a profile costs no allocations per call, and a profile is
+// safe for concurrent use by multiple goroutines.
+//
+// A profile replaces the settings of the options before it; options after it
+// change its settings for that call only.
+type Profile struct {
+	name string
+	cfg  config
+}
+
+// Profiles of shells whose quoting Split can follow.
+var (
+	// Bash follows Bash: in addition to the default rules, a
+	// backslash-newline within double quotes is removed, and the escapes
+	// of $'...' are decoded, as by WithANSICQuoting.
+	Bash = NewProfile("bash", WithQuotedContinuation(ContinuationRemove), WithANSICQuoting(true))
+
+	// POSIX follows the token recognition rules of POSIX.1-2017 sh, as
+	// implemented by dash and busybox sh, with no Bash extensions: $'...'
+	// and $"..." are not quotes, so that $"a" is split into $a; a
+	// backslash-newline is removed, outside quotes as well as within
+	// double quotes; and with WithOperators, Bash's |&, &>, &>> and <<<
+	// are split into POSIX operators, e.g. <<< into << and <.
+	POSIX = &Profile{name: "posix", cfg: config{continuation: ContinuationRemove, posix: true}}
+)
+
+// NewProfile returns a profile named name with the settings of opts.
+func NewProfile(name string, opts ...Option) *Profile {
+	return &Profile{name: name, cfg: newConfig(opts)}
+}
+
+func (p *Profile) apply(c *config) {
+	*c = p.cfg
+}
+
+// String returns the name of the profile.
+func (p *Profile) String() string {
+	return p.name
+}
@@ -0,0 +1,82 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"fmt"
+)
+
+// Origin is where the text of a FieldPart came from.
+type Origin uint8
+
+const (
+	// OriginLiteral is text written in the input, including expansions
+	// that were left as written.
+	OriginLiteral Origin = iota
+
+	// OriginParameter is text produced by a parameter expansion such as
+	// $NAME or ${NAME:-word}. The default word of ${NAME:-word} keeps
+	// its own origin.
+	OriginParameter
+
+	// OriginCommand is text produced by a command substitution.
+	OriginCommand
+
+	// OriginPathname is a pathname produced by pathname expansion.
+	OriginPathname
+)
+
+func (o Origin) String() string {
+	switch o {
+	case OriginLiteral:
+		return "literal"
+	case OriginParameter:
+		return "parameter"
+	case OriginCommand:
+		return "command"
+	case OriginPathname:
+		return "pathname"
+	}
+	return fmt.Sprintf("Origin(%d)", uint8(o))
+}
+
+// Field is a field produced by an Expander, along with where its text came
+// from.
+//
+// Expansion happens after the input has been split into tokens, so text from
+// an expansion can never become an operator. It can, however, become a
+// command name or an option; Expanded lets callers check for that, e.g. by
+// rejecting a command whose first field is not entirely literal.
+type Field struct {
+	Value string
+
+	// Parts divides Value into runs of text of the same origin. Their
+	// values concatenate to Value. Parts is empty if Value is.
+	Parts []FieldPart
+}
+
+// FieldPart is a run of text within a Field.
+type FieldPart struct {
+	Value  string
+	Origin Origin
+}
+
+// Expanded reports whether any text of the field came from an expansion.
+func (f Field) Expanded() bool {
+	for _, p := range f.Parts {
+		if p.Origin != OriginLiteral {
+			return true
+		}
+	}
+	return false
+}
+
+func fieldValues(fields []Field) []string {
+	ret := make([]string, 0, len(fields))
+	for _, f := range fields {
+		ret = append(ret, f.Value)
+	}
+	return ret
+}
@@ -0,0 +1,357 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Style selects how a single argument is quoted by QuoteStyle and
+// JoinStyles.
+type Style uint8
+
+const (
+	// StyleAuto is the most readable quoting: safe words are left bare and
+	// others single-quoted, unless they contain single quotes, which are
+	// then double-quoted if that needs no escapes, or else escaped with a
+	// backslash outside the single-quoted parts.
+	StyleAuto Style = iota
+
+	// StyleSingle always wraps the argument in single quotes. Embedded
+	// single quotes are written as '\''.
+	StyleSingle
+
+	// StyleDouble always wraps the argument in double quotes, escaping $,
+	// `, " and \ with a backslash.
+	StyleDouble
+
+	// StyleBare never wraps the argument in quotes, escaping special
+	// characters with a backslash instead. Newlines are single-quoted,
+	// since a backslash-newline is a line continuation in the shell.
+	StyleBare
+
+	// StyleANSIC uses Bash's $'...' quoting if the argument contains
+	// control characters or other non-printable characters, writing them
+	// as escape sequences such as \n or \x1b, and is StyleAuto
+	// otherwise. The result is understood by Bash, ksh and zsh, and by
+	// Split with WithANSICQuoting or the Bash profile, but not by POSIX sh.
+	StyleANSIC
+)
+
+// isSafe reports whether r never needs quoting.
+func isSafe(r rune) bool {
+	switch {
+	case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+		return true
+	}
+	switch r {
+	case '@', '%', '+', '=', ':', ',', '.', '/', '-', '_':
+		return true
+	}
+	return false
+}
+
+// needsQuoting reports whether s must be quoted to survive Split as a single
+// argument.
+func needsQuoting(s string) bool {
+	if len(s) == 0 {
+		return true
+	}
+	for _, r := range s {
+		if !isSafe(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrNotSingleWord is returned by Unquote for strings that are not exactly
+// one word.
+var ErrNotSingleWord = errors.New("not a single word")
+
+// Unquote removes the quotes and escapes of word, following the same rules as
+// Split, for words that have already been isolated by other means. It is the
+// inverse of Quote.
+//
+// Since word is known to be a word, a leading # is taken literally rather
+// than as the start of a comment. Unquote fails with ErrNotSingleWord if word
+// contains unquoted blanks, and with a *ParseError if it ends within quotes
+// or with a trailing backslash. The empty string unquotes to itself.
+func Unquote(word string) (string, error) {
+	tokens, err := Lex(word, WithComments(CommentLiteral))
+	switch {
+	case err != nil:
+		return "", err
+	case len(tokens) == 0 && len(word) == 0:
+		return "", nil
+	case len(tokens) != 1 || tokens[0].Raw != word:
+		return "", ErrNotSingleWord
+	}
+	return tokens[0].Value, nil
+}
+
+// Quote returns s quoted such that Split(Quote(s)) returns []string{s}, with
+// as little quoting as possible, as described for StyleAuto: it's is quoted
+// as "it's", for example.
+//
+// Words consisting only of safe characters are returned unchanged.
+func Quote(s string) string {
+	return QuoteStyle(s, StyleAuto)
+}
+
+// QuoteAlways is like Quote, but always single-quotes s, even if it is a safe
+// word, for callers that prefer uniform output to minimal output.
+func QuoteAlways(s string) string {
+	return quoteSingle(s)
+}
+
+// QuoteANSIC is like Quote, but uses Bash's $'...' quoting if s contains
+// control characters, other non-printable characters or invalid UTF-8, as
+// QuoteStyle does with StyleANSIC. Logged command lines quoted this way stay
+// on one line and can be pasted into Bash, ksh or zsh.
+func QuoteANSIC(s string) string {
+	return QuoteStyle(s, StyleANSIC)
+}
+
+// QuoteStyle quotes s using the given style. Split(QuoteStyle(s, style))
+// returns []string{s} for every style but StyleANSIC, which needs
+// WithANSICQuoting.
+func QuoteStyle(s string, style Style) string {
+	switch style {
+	case StyleSingle:
+		return quoteSingle(s)
+	case StyleDouble:
+		return quoteDouble(s)
+	case StyleBare:
+		return quoteBare(s)
+	case StyleANSIC:
+		if !printable(s) {
+			return quoteANSIC(s)
+		}
+	}
+
+	return quoteAuto(s)
+}
+
+func quoteAuto(s string) string {
+	switch {
+	case !needsQuoting(s):
+		return s
+	case !strings.ContainsRune(s, '\''):
+		return quoteSingle(s)
+	case !strings.ContainsAny(s, "$`\"\\!"):
+		// ! would be history expansion in interactive Bash.
+		return `"` + s + `"`
+	}
+
+	// Quote the parts between the single quotes separately, leaving out
+	// empty ones, e.g. 'a'\'' becomes a\'.
+	var b strings.Builder
+	for i, part := range strings.Split(s, "'") {
+		if i > 0 {
+			b.WriteString(`\'`)
+		}
+		if len(part) > 0 {
+			b.WriteString(quoteAuto(part))
+		}
+	}
+	return b.String()
+}
+
+// QuoteDialect is like Quote, but quotes s for dialect d, such that the shell
+// of d takes the result as the single argument s. For dialects other than
+// DialectFish, the result is also understood by Split.
+//
+// Fish takes backslashes within single quotes as escapes, so they are
+// doubled. Zsh expands words starting with = to command paths, and fish
+// words starting with % to process IDs, so such words are quoted for them.
+func QuoteDialect(s string, d Dialect) string {
+	switch d {
+	case DialectFish:
+		return quoteFish(s)
+	case DialectZsh:
+		if strings.HasPrefix(s, "=") && !needsQuoting(s) {
+			return quoteSingle(s)
+		}
+	}
+	return quoteAuto(s)
+}
+
+func quoteFish(s string) string {
+	switch {
+	case !needsQuoting(s) && !strings.HasPrefix(s, "%"):
+		return s
+	case strings.ContainsRune(s, '\'') && !strings.ContainsAny(s, "$\"\\"):
+		return `"` + s + `"`
+	}
+	var b strings.Builder
+	b.WriteByte('\'')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\'' || s[i] == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
+
+func quoteSingle(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func quoteDouble(s string) string {
+	return `"` + EscapeDoubleQuoted(s) + `"`
+}
+
+// EscapeDoubleQuoted escapes $, `, " and \ in s with a backslash, so that s
+// can be spliced into an existing double-quoted string, as in a template such
+// as "Hello, %s.", and is taken literally by Split and the shell.
+//
+// Interactive Bash also expands ! within double quotes, which no escape
+// prevents; a template used there can end the quotes before it, as in "a"'!'.
+func EscapeDoubleQuoted(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '$', '`', '"', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func quoteBare(s string) string {
+	if len(s) == 0 {
+		return "''"
+	}
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '\n':
+			b.WriteString("'\n'")
+		case isSafe(r):
+			b.WriteRune(r)
+		default:
+			_, size := utf8.DecodeRuneInString(s[i:])
+			b.WriteByte('\\')
+			b.WriteString(s[i : i+size])
+		}
+	}
+	return b.String()
+}
+
+// printable reports whether s is valid UTF-8 without non-printable
+// characters other than spaces.
+func printable(s string) bool {
+	for _, r := range s {
+		if r == utf8.RuneError || r != ' ' && !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// ansiEscapes are the named escape sequences of $'...'.
+var ansiEscapes = map[rune]string{
+	'\a': `\a`, '\b': `\b`, '\x1b': `\e`, '\f': `\f`, '\n': `\n`,
+	'\r': `\r`, '\t': `\t`, '\v': `\v`, '\\': `\\`, '\'': `\'`,
+}
+
+func quoteANSIC(s string) string {
+	var b strings.Builder
+	b.WriteString("$'")
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch esc, ok := ansiEscapes[r]; {
+		case ok:
+			b.WriteString(esc)
+		case r == utf8.RuneError && size == 1:
+			fmt.Fprintf(&b, `\x%02x`, s[i])
+		case r == ' ' || unicode.IsPrint(r):
+			b.WriteString(s[i : i+size])
+		case r < 0x80:
+			fmt.Fprintf(&b, `\x%02x`, r)
+		case r <= 0xffff:
+			// \u and \U take up to 4 and 8 hex digits, so always
+			// writing all of them keeps the next character intact.
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			fmt.Fprintf(&b, `\U%08x`, r)
+		}
+		i += size
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
+
+// Join quotes each argument with Quote and joins them with spaces, such that
+// Split(Join(args)) returns args, e.g. for logging an exec.Cmd or building
+// the command of an ssh invocation.
+//
+// This holds for all arguments, including empty ones and those containing
+// quotes, newlines, NUL bytes or invalid UTF-8, and equally for JoinStyles
+// with every Style, given WithANSICQuoting for StyleANSIC; the shlextest
+// package checks it in fuzz tests.
+func Join(args []string) string {
+	return JoinStyles(args, nil)
+}
+
+// EscapeBackquoted escapes \, ` and $ in the command line s with a backslash,
+// so that s can be written within backquotes, as in `s`. Within backquotes,
+// the shell removes a backslash before these characters before running the
+// command, so s is run as is: EscapeBackquoted(Join(argv)) runs argv. Nested
+// backquotes need an escape per level, obtained by applying EscapeBackquoted
+// again.
+//
+// Within $(...), the command is parsed like any other, so Quote and Join
+// need no further escaping there, however deeply $(...) is nested. That
+// makes $(...) the easier choice for commands built at run time.
+func EscapeBackquoted(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\', '`', '$':
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// QuoteBackquoted is like Quote, but escapes the result as EscapeBackquoted
+// does, for use as a single argument of a command within backquotes.
+func QuoteBackquoted(s string) string {
+	return EscapeBackquoted(Quote(s))
+}
+
+// JoinDialect is like Join, but quotes each argument with QuoteDialect for
+// dialect d.
+func JoinDialect(args []string, d Dialect) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = QuoteDialect(arg, d)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// JoinStyles is like Join, but quotes args[i] using styles[i]. Arguments
+// without a corresponding entry in styles use StyleAuto.
+func JoinStyles(args []string, styles []Style) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		style := StyleAuto
+		if i < len(styles) {
+			style = styles[i]
+		}
+		quoted[i] = QuoteStyle(arg, style)
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,817 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	// ErrUnterminatedSingleQuote is returned when the input ends within
+	// single quotes.
+	ErrUnterminatedSingleQuote = errors.New("unterminated single quote")
+
+	// ErrUnterminatedDoubleQuote is returned when the input ends within
+	// double quotes.
+	ErrUnterminatedDoubleQuote = errors.New("unterminated double quote")
+
+	// ErrTrailingBackslash is returned when the input ends with a
+	// backslash that escapes nothing.
+	ErrTrailingBackslash = errors.New("trailing backslash")
+)
+
+type state uint8
+
+const (
+	unquoted state = iota
+	escape
+	singleQuote
+	doubleQuote
+	doubleQuoteEscape
+	comment
+
+	// ansiCEscape follows a backslash within $'...' decoded according to
+	// WithANSICQuoting.
+	ansiCEscape
+)
+
+// isMeta reports whether r is one of the characters that, unquoted, make
+// up shell operators such as | and &&. Split does not treat them specially
+// unless WithOperators is given.
+func isMeta(r rune) bool {
+	switch r {
+	case '|', '&', ';', '<', '>', '(', ')':
+		return true
+	}
+	return false
+}
+
+// operators are the shell operators split off by WithOperators. Every prefix
+// of an operator is itself an operator.
+var operators = map[string]struct{}{
+	"|": {}, "||": {}, "|&": {},
+	"&": {}, "&&": {}, "&>": {}, "&>>": {},
+	";": {}, ";;": {},
+	"<": {}, "<<": {}, "<<-": {}, "<<<": {}, "<>": {}, "<&": {},
+	">": {}, ">>": {}, ">&": {}, ">|": {},
+	"(": {}, ")": {},
+}
+
+// bashOperators are the operators that POSIX sh does not have.
+var bashOperators = map[string]struct{}{
+	"|&": {}, "&>": {}, "&>>": {}, "<<<": {},
+}
+
+// word is a single word read by the scanner.
+type word struct {
+	value string
+
+	// start and end are the byte offsets of the raw, still quoted word
+	// in the input.
+	start int
+	end   int
+
+	// pos is the position of the start of the word, and endPos that just
+	// past its end.
+	pos    Position
+	endPos Position
+
+	// bare is the byte length of the leading part of value that was
+	// neither quoted nor escaped, and quoted is set if any part was.
+	bare   int
+	quoted bool
+
+	// meta is set if the word contains an unquoted operator character
+	// (see isMeta).
+	meta bool
+
+	// glob is set if the word contains an unquoted *, ? or [, brace if it
+	// contains an unquoted { and tilde if it starts with an unquoted ~.
+	glob  bool
+	brace bool
+	tilde bool
+
+	// operator is set if the word is an operator split off by
+	// WithOperators, and comment if it is a comment kept by CommentKeep.
+	operator bool
+	comment  bool
+
+	// At the end of the input, unterminated is the error describing the
+	// quote or escape left open by the word, if any, open is its position
+	// and suffix the text that closes it.
+	unterminated error
+	open         Position
+	suffix       string
+}
+
+// scanner is the state machine behind Split. It reads runes one at a time and
+// returns one word at a time.
+type scanner struct {
+	in  io.RuneScanner
+	cfg *config
+
+	// pos is the position of the next rune in the input.
+	pos Position
+
+	// count is the number of words returned so far.
+	count int
+
+	context state
+
+	// started is set once a word has begun, even if it is still empty
+	// (e.g. after '' or "").
+	started bool
+	token   []rune
+	start   Position
+
+	// open is the position of the quote or backslash that began the
+	// current context.
+	open Position
+
+	// errPos is the position at which a *BudgetError was returned.
+	errPos Position
+
+	// bare is the number of leading runes of token that were neither
+	// quoted nor escaped, and quoted is set once a quote or escape has
+	// been seen in the word.
+	bare   int
+	quoted bool
+	meta   bool
+	glob   bool
+	brace  bool
+	tilde  bool
+
+	// With WithOperators, nest holds the closing brackets of the $(...)
+	// and ${...} expansions the word is in, backquote is set within
+	// `...`, and dollar is set after an unquoted $.
+	nest      []rune
+	backquote bool
+	dollar    bool
+
+	// lastDollar is set if the last rune of token is an unquoted $. ansiC
+	// is set within single quotes opened by $', and quoteAt is the length
+	// of token when they were opened.
+	lastDollar bool
+	ansiC      bool
+	quoteAt    int
+
+	// letter is set if the last rune of token is an unquoted letter.
+	letter bool
+
+	// closed is set once a quote of the word has been closed, which makes
+	// it a word even if it is empty.
+	closed bool
+
+	// discard is set if the values of words are not needed, so that they
+	// are not built.
+	discard bool
+
+	// shared is set if the input is shared with the caller, so that the
+	// blank ending a word is left unread.
+	shared bool
+
+	// done is the Done channel of the context of WithContext.
+	done <-chan struct{}
+}
+
+func newScanner(in io.RuneScanner, cfg *config) *scanner {
+	s := &scanner{cfg: cfg}
+	s.reset(in)
+	return s
+}
+
+// reset makes s read from in, from the start, keeping its buffers.
+func (s *scanner) reset(in io.RuneScanner) {
+	*s = scanner{
+		in:      in,
+		cfg:     s.cfg,
+		pos:     Position{Line: 1, Column: 1},
+		token:   s.token[:0],
+		nest:    s.nest[:0],
+		discard: s.discard,
+	}
+	if s.cfg.utf16 {
+		s.pos.UTF16Column = 1
+	}
+	if s.cfg.ctx != nil {
+		s.done = s.cfg.ctx.Done()
+	}
+}
+
+// invalidBase is added to an invalid byte of the input to keep it as a rune
+// of token, in the range of the surrogate halves that UTF-8 cannot encode, so
+// that tokenString can restore it.
+const invalidBase = 0xdc00
+
+// invalidByte returns the invalid byte just read as a rune, as ReadRune
+// returns utf8.RuneError for it, if in can give it back as a byte.
+func (s *scanner) invalidByte() rune {
+	bs, ok := s.in.(io.ByteScanner)
+	if ok && s.in.UnreadRune() == nil {
+		if b, err := bs.ReadByte(); err == nil {
+			return invalidBase + rune(b)
+		}
+	}
+	return utf8.RuneError
+}
+
+// tokenString returns token as a string, with invalid bytes restored.
+func tokenString(token []rune) string {
+	i := 0
+	for i < len(token) && (token[i] < invalidBase+0x80 || token[i] > invalidBase+0xff) {
+		i++
+	}
+	if i == len(token) {
+		return string(token)
+	}
+
+	b := []byte(string(token[:i]))
+	var enc [utf8.UTFMax]byte
+	for _, r := range token[i:] {
+		if invalidBase+0x80 <= r && r <= invalidBase+0xff {
+			b = append(b, byte(r-invalidBase))
+			continue
+		}
+		n := utf8.EncodeRune(enc[:], r)
+		b = append(b, enc[:n]...)
+	}
+	return string(b)
+}
+
+// emit returns the current word, ending at end, and resets the word state.
+func (s *scanner) emit(end Position) word {
+	w := word{
+		start:  s.start.Offset,
+		end:    end.Offset,
+		pos:    s.start,
+		endPos: end,
+		quoted: s.quoted,
+		meta:   s.meta,
+		glob:   s.glob,
+		brace:  s.brace,
+		tilde:  s.tilde,
+	}
+	if !s.discard {
+		w.value = tokenString(s.token)
+		w.bare = len(tokenString(s.token[:s.bare]))
+	}
+	s.token = s.token[:0]
+	s.started = false
+	s.bare = 0
+	s.quoted = false
+	s.meta = false
+	s.glob = false
+	s.brace = false
+	s.tilde = false
+	s.nest = s.nest[:0]
+	s.backquote = false
+	s.dollar = false
+	s.lastDollar = false
+	s.ansiC = false
+	s.letter = false
+	s.closed = false
+	return w
+}
+
+// finish is like emit, but enforces the token budget.
+func (s *scanner) finish(end Position) (word, error) {
+	if s.cfg.maxTokens > 0 && s.count >= s.cfg.maxTokens {
+		s.errPos = s.start
+		return word{}, &BudgetError{Limit: TokenLimit, Max: s.cfg.maxTokens, Offset: s.start.Offset}
+	}
+	if s.tooLong(end) {
+		return word{}, s.tooLongErr()
+	}
+	s.count++
+	return s.emit(end), nil
+}
+
+// tooLong reports whether the current word, continuing up to p, exceeds the
+// limit of WithMaxTokenLen.
+func (s *scanner) tooLong(p Position) bool {
+	return s.cfg.maxTokenLen > 0 && s.started && p.Offset-s.start.Offset > s.cfg.maxTokenLen
+}
+
+func (s *scanner) tooLongErr() error {
+	s.errPos = s.start
+	return &BudgetError{Limit: TokenLenLimit, Max: s.cfg.maxTokenLen, Offset: s.start.Offset}
+}
+
+// begin marks the start of a word at p, unless one has already begun.
+func (s *scanner) begin(p Position) {
+	if !s.started {
+		s.started = true
+		s.start = p
+	}
+}
+
+// enter switches to a quoting context opened by the rune at p.
+func (s *scanner) enter(context state, p Position) {
+	s.context = context
+	s.open = p
+	s.begin(p)
+	s.quoted = true
+	s.dollar = false
+	s.lastDollar = false
+	s.letter = false
+}
+
+// dropDollar removes the unquoted $ at the end of token, as Bash does for
+// the $ of $"..." and of an empty $'...'.
+func (s *scanner) dropDollar() {
+	s.token = s.token[:len(s.token)-1]
+	if s.bare > len(s.token) {
+		s.bare = len(s.token)
+	}
+}
+
+// blank reports whether the unquoted rune r separates words.
+func (s *scanner) blank(r rune) bool {
+	if len(s.cfg.ifs) > 0 {
+		return strings.ContainsRune(s.cfg.ifs, r)
+	}
+	return unicode.IsSpace(r)
+}
+
+// skipCR reports whether r is a carriage return to be dropped according to
+// WithStripCR.
+func (s *scanner) skipCR(r rune) bool {
+	if !s.cfg.stripCR || r != '\r' {
+		return false
+	}
+	next, _, err := s.in.ReadRune()
+	if err == nil {
+		_ = s.in.UnreadRune()
+		return next == '\n'
+	}
+	// Other errors surface on the next read.
+	return err == io.EOF
+}
+
+// continued reports whether the unquoted backslash just read begins a line
+// continuation, which POSIX sh removes before splitting words, and if so reads
+// the newline.
+func (s *scanner) continued() bool {
+	next, size, err := s.in.ReadRune()
+	if err != nil {
+		// Other errors surface on the next read.
+		return false
+	}
+	if next != '\n' || s.cfg.maxBytes > 0 && s.pos.Offset+size > s.cfg.maxBytes {
+		_ = s.in.UnreadRune()
+		return false
+	}
+	s.advance(next, size)
+	return true
+}
+
+// ansiCDecodes are the runes of the named escape sequences of $'...'.
+var ansiCDecodes = map[rune]rune{
+	'a': '\a', 'b': '\b', 'e': '\x1b', 'E': '\x1b', 'f': '\f', 'n': '\n',
+	'r': '\r', 't': '\t', 'v': '\v', '\\': '\\', '\'': '\'', '"': '"', '?': '?',
+}
+
+// decodeEscape appends the character of the escape sequence of $'...' that
+// starts with r, following the backslash, as Bash does: \n and the like,
+// \xHH, \uHHHH and \UHHHHHHHH with up to as many hex digits, \nnn with up to
+// three octal digits, and \cx for control-x. Other escapes are kept as is.
+func (s *scanner) decodeEscape(r rune) {
+	if c, ok := ansiCDecodes[r]; ok {
+		s.token = append(s.token, c)
+		return
+	}
+	switch r {
+	case '0', '1', '2', '3', '4', '5', '6', '7':
+		v, _ := s.digits(r-'0', 8, 2)
+		s.appendByte(byte(v))
+		return
+	case 'x':
+		if v, n := s.digits(0, 16, 2); n > 0 {
+			s.appendByte(byte(v))
+			return
+		}
+	case 'u', 'U':
+		max := 4
+		if r == 'U' {
+			max = 8
+		}
+		if v, n := s.digits(0, 16, max); n > 0 {
+			if !utf8.ValidRune(v) {
+				v = utf8.RuneError
+			}
+			s.token = append(s.token, v)
+			return
+		}
+	case 'c':
+		next, size, err := s.in.ReadRune()
+		if err == nil && next != '\'' && (s.cfg.maxBytes == 0 || s.pos.Offset+size <= s.cfg.maxBytes) {
+			s.advance(next, size)
+			s.token = append(s.token, next&0x1f)
+			return
+		}
+		if err == nil {
+			_ = s.in.UnreadRune()
+		}
+	}
+	s.token = append(s.token, '\\', r)
+}
+
+// digits reads up to max more digits in base for a number starting with v,
+// returning the number and how many digits were read.
+func (s *scanner) digits(v, base rune, max int) (rune, int) {
+	n := 0
+	for ; n < max; n++ {
+		r, size, err := s.in.ReadRune()
+		if err != nil {
+			break
+		}
+		d := digitValue(r)
+		if d >= base || s.cfg.maxBytes > 0 && s.pos.Offset+size > s.cfg.maxBytes {
+			_ = s.in.UnreadRune()
+			break
+		}
+		s.advance(r, size)
+		v = v*base + d
+	}
+	return v, n
+}
+
+// digitValue returns the value of the hex digit r, or 16 if r is none.
+func digitValue(r rune) rune {
+	switch {
+	case '0' <= r && r <= '9':
+		return r - '0'
+	case 'a' <= r && r <= 'f':
+		return r - 'a' + 10
+	case 'A' <= r && r <= 'F':
+		return r - 'A' + 10
+	}
+	return 16
+}
+
+// appendByte appends the byte b to token, as a rune if it is ASCII and
+// otherwise as an invalid byte restored by tokenString.
+func (s *scanner) appendByte(b byte) {
+	if b < utf8.RuneSelf {
+		s.token = append(s.token, rune(b))
+		return
+	}
+	s.token = append(s.token, invalidBase+rune(b))
+}
+
+// advance moves pos past r, which is size bytes long.
+func (s *scanner) advance(r rune, size int) {
+	s.pos.Offset += size
+	if r == '\n' {
+		s.pos.Line++
+		s.pos.Column = 1
+	} else {
+		s.pos.Column++
+	}
+	if s.cfg.utf16 {
+		units := 1
+		if r >= 0x10000 {
+			// A surrogate pair.
+			units = 2
+		}
+		s.pos.UTF16Offset += units
+		if r == '\n' {
+			s.pos.UTF16Column = 1
+		} else {
+			s.pos.UTF16Column += units
+		}
+	}
+}
+
+// substitution tracks the expansions and command substitutions of the word,
+// and reports whether the unquoted rune r is part of one, so that blanks and
+// operators within it do not end the word.
+func (s *scanner) substitution(r rune) bool {
+	dollar := s.dollar
+	s.dollar = r == '$'
+	switch {
+	case r == '`':
+		s.backquote = !s.backquote
+		return true
+	case s.backquote:
+		return true
+	case dollar && r == '(':
+		s.nest = append(s.nest, ')')
+		return true
+	case dollar && r == '{':
+		s.nest = append(s.nest, '}')
+		return true
+	case len(s.nest) == 0:
+		return false
+	case r == s.nest[len(s.nest)-1]:
+		s.nest = s.nest[:len(s.nest)-1]
+	case r == '(' && s.nest[len(s.nest)-1] == ')':
+		s.nest = append(s.nest, ')')
+	}
+	return true
+}
+
+// apostrophe reports whether the unquoted single quote just read is a
+// literal apostrophe according to WithApostrophes.
+func (s *scanner) apostrophe() bool {
+	if !s.cfg.apostrophes || !s.letter {
+		return false
+	}
+	next, _, err := s.in.ReadRune()
+	if err != nil {
+		// Errors other than io.EOF surface on the next read.
+		return false
+	}
+	_ = s.in.UnreadRune()
+	return unicode.IsLetter(next)
+}
+
+// operator reads the longest operator starting with r, which is at p, or
+// just r if it is a terminator of WithTerminators.
+func (s *scanner) operator(r rune, p Position) (word, error) {
+	s.begin(p)
+	s.token = append(s.token, r)
+	for s.cfg.operators && isMeta(r) {
+		next, size, err := s.in.ReadRune()
+		if err != nil {
+			// Errors other than io.EOF surface on the next read.
+			break
+		}
+		op := string(append(s.token, next))
+		_, ok := operators[op]
+		if _, bash := bashOperators[op]; bash && s.cfg.posix {
+			ok = false
+		}
+		if !ok || s.cfg.maxBytes > 0 && s.pos.Offset+size > s.cfg.maxBytes {
+			_ = s.in.UnreadRune()
+			break
+		}
+		s.advance(next, size)
+		s.token = append(s.token, next)
+	}
+	w, err := s.finish(s.pos)
+	w.operator = err == nil
+	return w, err
+}
+
+// unterminated returns the error describing the quote or escape left open
+// at the end of the input, if any. It is only meaningful after next has
+// returned io.EOF.
+func (s *scanner) unterminated() error {
+	switch s.context {
+	case singleQuote, ansiCEscape:
+		return ErrUnterminatedSingleQuote
+	case doubleQuote, doubleQuoteEscape:
+		return ErrUnterminatedDoubleQuote
+	case escape:
+		return ErrTrailingBackslash
+	}
+	return nil
+}
+
+// suffix returns the text that closes the quote or escape left open at the
+// end of the input, if any.
+func (s *scanner) suffix() string {
+	switch s.context {
+	case singleQuote:
+		return "'"
+	case doubleQuote:
+		return `"`
+	case doubleQuoteEscape:
+		return `\"`
+	case escape:
+		return `\`
+	case ansiCEscape:
+		return `\'`
+	}
+	return ""
+}
+
+// next returns the next word, or io.EOF if there are none left.
+func (s *scanner) next() (word, error) {
+	for {
+		if s.done != nil {
+			select {
+			case <-s.done:
+				return word{}, s.cfg.ctx.Err()
+			default:
+			}
+		}
+		r, size, err := s.in.ReadRune()
+		if err != nil {
+			if err == io.EOF && s.started {
+				if s.context == ansiCEscape {
+					// Keep the backslash, as closing the quote
+					// with suffix would.
+					s.token = append(s.token, '\\')
+				}
+				if len(s.token) == 0 && !s.closed && s.context != unquoted && s.context != comment {
+					// A trailing backslash or an open quote
+					// alone is not a word.
+					return word{}, err
+				}
+				w, err := s.finish(s.pos)
+				if err == nil {
+					w.comment = s.context == comment
+					w.unterminated = s.unterminated()
+					w.open = s.open
+					w.suffix = s.suffix()
+				}
+				return w, err
+			}
+			return word{}, err
+		}
+		if r == utf8.RuneError && size == 1 {
+			r = s.invalidByte()
+		}
+		p := s.pos
+		if s.cfg.maxBytes > 0 && p.Offset+size > s.cfg.maxBytes {
+			s.errPos = p
+			return word{}, &BudgetError{Limit: ByteLimit, Max: s.cfg.maxBytes, Offset: p.Offset}
+		}
+		if s.tooLong(p) {
+			// The word ends at p at the earliest.
+			return word{}, s.tooLongErr()
+		}
+		s.advance(r, size)
+
+		if s.skipCR(r) {
+			continue
+		}
+
+		quotes := s.context != unquoted
+		switch s.context {
+		case unquoted:
+			switch r {
+			case '\\':
+				if s.cfg.posix && s.continued() {
+					continue
+				}
+				s.enter(escape, p)
+				// strip out the quote
+				continue
+			case '\'':
+				if s.apostrophe() {
+					break
+				}
+				s.ansiC = s.lastDollar && !s.cfg.posix
+				if s.ansiC && s.cfg.decodeANSIC {
+					s.dropDollar()
+				}
+				s.enter(singleQuote, p)
+				s.quoteAt = len(s.token)
+				// strip out the quote
+				continue
+			case '"':
+				// $"..." is translated according to the locale,
+				// which in the C locale leaves it as is.
+				if s.lastDollar && !s.cfg.posix {
+					s.dropDollar()
+				}
+				s.enter(doubleQuote, p)
+				// strip out the quote
+				continue
+			case '#':
+				if !s.started && s.cfg.comments != CommentLiteral {
+					s.context = comment
+					if s.cfg.comments == CommentKeep {
+						s.begin(p)
+						s.token = append(s.token, r)
+					}
+					// strip out the rest
+					continue
+				}
+			}
+			if s.cfg.operators && s.substitution(r) {
+				s.begin(p)
+				if !s.quoted {
+					s.bare++
+				}
+				s.token = append(s.token, r)
+				s.lastDollar = false
+				continue
+			}
+			if s.cfg.operators && isMeta(r) || strings.ContainsRune(s.cfg.terminators, r) {
+				if s.started {
+					// End the word here and read the operator next
+					// time.
+					_ = s.in.UnreadRune()
+					s.pos = p
+					return s.finish(p)
+				}
+				return s.operator(r, p)
+			}
+
+		case escape:
+			s.context = unquoted
+
+		case singleQuote:
+			if r == '\\' && s.ansiC && s.cfg.decodeANSIC {
+				s.context = ansiCEscape
+				// strip out the backslash
+				continue
+			}
+			if r == '\'' {
+				if s.ansiC && !s.cfg.decodeANSIC && len(s.token) == s.quoteAt {
+					// $'' is empty.
+					s.dropDollar()
+				}
+				s.ansiC = false
+				s.context = unquoted
+				s.closed = true
+				// strip out the quote
+				continue
+			}
+
+		case ansiCEscape:
+			s.context = singleQuote
+			s.decodeEscape(r)
+			continue
+
+		case doubleQuote:
+			switch {
+			case r == '\\' && !s.cfg.rawDoubleQuotes:
+				s.context = doubleQuoteEscape
+				// strip out the quote
+				continue
+			case r == '"':
+				s.context = unquoted
+				s.closed = true
+				// strip out the quote
+				continue
+			}
+
+		case doubleQuoteEscape:
+			// GNU Bash manual:
+			//
+			// The backslash retains its special meaning only when
+			// followed by one of the following characters: ‘$’,
+			// ‘`’, ‘"’, ‘\’, or newline. Within double quotes,
+			// backslashes that are followed by one of these
+			// characters are removed.
+			s.context = doubleQuote
+			switch r {
+			case '\n':
+				switch s.cfg.continuation {
+				case ContinuationRemove:
+					continue
+				case ContinuationLiteral:
+					s.token = append(s.token, '\\')
+				}
+			case '$', '"', '\\', '`':
+			default:
+				s.token = append(s.token, '\\')
+			}
+
+		case comment:
+			switch {
+			case r == '\n':
+				s.context = unquoted
+				if s.started {
+					w, err := s.finish(p)
+					w.comment = err == nil
+					return w, err
+				}
+			case s.cfg.comments == CommentKeep:
+				s.token = append(s.token, r)
+			}
+
+			// strip out the rest
+			continue
+		}
+
+		if quotes || !s.blank(r) {
+			s.begin(p)
+			if !quotes && !s.quoted {
+				s.bare++
+			}
+			if !quotes {
+				switch r {
+				case '*', '?', '[':
+					s.glob = true
+				case '{':
+					s.brace = true
+				case '~':
+					s.tilde = s.tilde || len(s.token) == 0 && !s.quoted
+				default:
+					s.meta = s.meta || isMeta(r)
+				}
+			}
+			s.token = append(s.token, r)
+			// In $$'', the $ belongs to $$.
+			s.lastDollar = !quotes && r == '$' && !s.lastDollar
+			s.letter = !quotes && unicode.IsLetter(r)
+		} else if s.started {
+			if s.shared && s.in.UnreadRune() == nil {
+				s.pos = p
+			}
+			return s.finish(p)
+		}
+	}
+}
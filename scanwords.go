@@ -0,0 +1,36 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"bytes"
+	"io"
+)
+
+// ScanShellWords is a split function for a bufio.Scanner that returns each
+// shell word, with quotes removed, as by Split. It never returns an error:
+// as with Split, a quote left open at the end of the input is taken to be
+// closed.
+//
+// Since words may span lines, the Scanner's buffer must be able to hold the
+// longest word, with any comment preceding it.
+func ScanShellWords(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	var cfg config
+	sc := newScanner(bytes.NewReader(data), &cfg)
+	w, err := sc.next()
+	if err == io.EOF {
+		if atEOF || sc.context == unquoted {
+			// Only blanks, or a complete comment.
+			return len(data), nil, nil
+		}
+		// Within a comment.
+		return 0, nil, nil
+	}
+	if sc.pos.Offset == len(data) && !atEOF {
+		// The word may continue.
+		return 0, nil, nil
+	}
+	return sc.pos.Offset, []byte(w.value), nil
+}
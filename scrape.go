@@ -0,0 +1,97 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"strings"
+)
+
+// SplitBestEffort splits a command line scraped from a log, where it may be
+// colored, truncated or mangled. It never fails: confident reports whether
+// line was split as is, apart from the removal of ANSI escape sequences.
+//
+// ANSI escape sequences, such as color codes, are removed first, as by
+// StripANSI. Then, as long as the line ends within quotes, the quote that
+// was left open is taken literally, as it is most likely a stray apostrophe
+// such as in "don't", and a trailing backslash is dropped. Either repair
+// clears confident.
+func SplitBestEffort(line string) (args []string, confident bool) {
+	line = StripANSI(line)
+	confident = true
+	for {
+		cfg := newConfig(nil)
+		sc := newScanner(strings.NewReader(line), &cfg)
+		var words []word
+		var err error
+		for err == nil {
+			var w word
+			if w, err = sc.next(); err == nil {
+				words = append(words, w)
+			}
+		}
+
+		switch sc.unterminated() {
+		case nil:
+			return values(words), confident
+		case ErrTrailingBackslash:
+			line = line[:len(line)-1]
+		default:
+			o := sc.open.Offset
+			line = line[:o] + `\` + line[o:]
+		}
+		confident = false
+	}
+}
+
+// StripANSI removes the ANSI escape sequences from s: control sequences such
+// as the color code "\x1b[31m", operating system commands such as terminal
+// titles and hyperlinks, and other two-character escapes. An incomplete
+// sequence at the end of s is removed too.
+func StripANSI(s string) string {
+	if strings.IndexByte(s, '\x1b') < 0 {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if s[i] != '\x1b' {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+		i = ansiEnd(s, i)
+	}
+	return b.String()
+}
+
+// ansiEnd returns the index just past the escape sequence starting at s[i].
+func ansiEnd(s string, i int) int {
+	i++
+	if i >= len(s) {
+		return i
+	}
+	switch s[i] {
+	case '[':
+		// CSI: parameter and intermediate bytes, then a final byte.
+		for i++; i < len(s); i++ {
+			if 0x40 <= s[i] && s[i] <= 0x7e {
+				return i + 1
+			}
+		}
+		return i
+
+	case ']', 'P', '_', '^':
+		// OSC and other strings, terminated by BEL or ESC \.
+		for i++; i < len(s); i++ {
+			if s[i] == '\a' {
+				return i + 1
+			}
+			if s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '\\' {
+				return i + 2
+			}
+		}
+		return i
+	}
+	return i + 1
+}
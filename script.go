@@ -0,0 +1,26 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"strings"
+)
+
+// EmbedScript returns a command that runs script when passed through one
+// layer of shell, of the form
+//
+//	sh -c 'script'
+//
+// so that it can be sent, e.g., as the command of ssh. Single quotes in
+// script are quoted as by Quote, and newlines are kept.
+//
+// If script contains a NUL byte, which cannot be passed through a shell,
+// EmbedScript returns ErrNUL.
+func EmbedScript(script string) (string, error) {
+	if strings.IndexByte(script, 0) >= 0 {
+		return "", ErrNUL
+	}
+	return "sh -c " + QuoteStyle(script, StyleSingle), nil
+}
@@ -12,18 +12,9 @@
 package shlex
 
 import (
-	"unicode"
-)
-
-type state uint8
-
-const (
-	unquoted state = iota
-	escape
-	singleQuote
-	doubleQuote
-	doubleQuoteEscape
-	comment
+	"fmt"
+	"io"
+	"strings"
 )
 
 // Split splits a command line according to Bash shell rules.
@@ -35,97 +26,135 @@ const (
 //
 // Split treats $, ", \, \n, and ` as special within double quotes, as does
 // Bash. This is slightly different from GRUB, but Grub can live with it.
-func Split(s string) []string {
-	ret := []string{}
-	var token []rune
-
-	var context state
-	lastWhiteSpace := true
-	for _, r := range s {
-		quotes := context != unquoted
-		switch context {
-		case unquoted:
-			switch r {
-			case '\\':
-				context = escape
-				// strip out the quote
-				continue
-			case '\'':
-				context = singleQuote
-				// strip out the quote
-				continue
-			case '"':
-				context = doubleQuote
-				// strip out the quote
-				continue
-			case '#':
-				if lastWhiteSpace {
-					context = comment
-					// strip out the rest
-					continue
-				}
-			}
+//
+// Quoted empty strings, such as a pair of single or double quotes, produce
+// empty arguments. So do the empty forms of Bash's $'...' and $"..." quoting;
+// the $ of $"..." is dropped, as Bash does in the C locale.
+//
+// Bytes that are not valid UTF-8 are kept as is, so that Split(Join(args))
+// returns args for arbitrary arguments.
+//
+// Split is tolerant: if s ends within quotes, they are taken to be closed,
+// and a trailing backslash is dropped. Use SplitErr to report these as
+// errors.
+func Split(s string, opts ...Option) []string {
+	return AppendSplit([]string{}, s, opts...)
+}
 
-		case escape:
-			context = unquoted
+// AppendSplit is like Split, but appends the words to dst and returns the
+// extended slice, so that a loop splitting many lines can reuse one:
+//
+//	args = shlex.AppendSplit(args[:0], line)
+func AppendSplit(dst []string, s string, opts ...Option) []string {
+	cfg := newConfig(opts)
+	sc := newScanner(strings.NewReader(s), &cfg)
+	for {
+		w, err := sc.next()
+		if err != nil {
+			return dst
+		}
+		dst = append(dst, w.value)
+	}
+}
 
-		case singleQuote:
-			if r == '\'' {
-				context = unquoted
-				// strip out the quote
-				continue
-			}
+// SplitN is like Split, but returns at most n words, as strings.SplitN does:
+// the last one is the rest of s from the start of the nth word, left
+// untouched, quotes and all. If n is zero, SplitN returns nil, and if n is
+// negative, it returns all words.
+//
+// For example, SplitN(`alias ll='ls -l' # long`, 2) returns "alias" and
+// `ll='ls -l' # long`.
+func SplitN(s string, n int, opts ...Option) []string {
+	if n == 0 {
+		return nil
+	}
+	if n < 0 {
+		return Split(s, opts...)
+	}
 
-		case doubleQuote:
-			switch r {
-			case '\\':
-				context = doubleQuoteEscape
-				// strip out the quote
-				continue
-			case '"':
-				context = unquoted
-				// strip out the quote
-				continue
-			}
+	cfg := newConfig(opts)
+	sc := newScanner(strings.NewReader(s), &cfg)
+	ret := []string{}
+	for {
+		w, err := sc.next()
+		if err != nil {
+			return ret
+		}
+		if len(ret) == n-1 {
+			return append(ret, s[w.start:])
+		}
+		ret = append(ret, w.value)
+	}
+}
 
-		case doubleQuoteEscape:
-			// GNU Bash manual:
-			//
-			// The backslash retains its special meaning only when
-			// followed by one of the following characters: ‘$’,
-			// ‘`’, ‘"’, ‘\’, or newline. Within double quotes,
-			// backslashes that are followed by one of these
-			// characters are removed.
-			switch r {
-			case '$', '"', '\\', '\n', '`': // or newline
-			default:
-				token = append(token, '\\')
-			}
+// SplitErr is like Split, but fails if s ends within quotes or with a
+// trailing backslash. The error is a *ParseError wrapping
+// ErrUnterminatedSingleQuote, ErrUnterminatedDoubleQuote or
+// ErrTrailingBackslash, positioned at the quote or backslash that was left
+// open.
+func SplitErr(s string, opts ...Option) ([]string, error) {
+	tokens, err := Lex(s, opts...)
+	if err != nil {
+		return nil, err
+	}
 
-			context = doubleQuote
+	ret := []string{}
+	for _, t := range tokens {
+		ret = append(ret, t.Value)
+	}
+	return ret, nil
+}
 
-		case comment:
-			switch r {
-			case '\n':
-				context = unquoted
-			}
+// MustSplit is like SplitErr, but panics if s cannot be split. It simplifies
+// the initialization of variables holding known-good command lines, and
+// tests.
+func MustSplit(s string, opts ...Option) []string {
+	args, err := SplitErr(s, opts...)
+	if err != nil {
+		panic(fmt.Sprintf("shlex: MustSplit(%q): %v", s, err))
+	}
+	return args
+}
 
-			// strip out the rest
-			continue
+// Validate checks that s can be split without error, as by SplitErr, without
+// building the words, for cheap checking of many stored command lines. It
+// returns a *ParseError if s ends within quotes or with a trailing
+// backslash, or a limit is exceeded.
+func Validate(s string, opts ...Option) error {
+	l := &Lexer{cfg: newConfig(opts)}
+	l.sc = newScanner(strings.NewReader(s), &l.cfg)
+	l.sc.discard = true
+	for {
+		res := l.read()
+		if res.err == io.EOF {
+			return nil
 		}
-
-		lastWhiteSpace = unicode.IsSpace(r)
-
-		if !lastWhiteSpace || quotes {
-			token = append(token, r)
-		} else if len(token) > 0 {
-			ret = append(ret, string(token))
-			token = token[:0]
+		if res.err != nil {
+			return res.err
 		}
 	}
+}
+
+// splitWords is like Split, but returns the words with their offsets. If s
+// ends within quotes or with a backslash, splitWords returns all words along
+// with the error describing what was left open.
+func splitWords(s string, opts []Option) ([]word, error) {
+	cfg := newConfig(opts)
+	return lexString(s, &cfg)
+}
+
+func lexString(s string, cfg *config) ([]word, error) {
+	sc := newScanner(strings.NewReader(s), cfg)
 
-	if len(token) > 0 {
-		ret = append(ret, string(token))
+	var words []word
+	for {
+		w, err := sc.next()
+		if err == io.EOF {
+			return words, sc.unterminated()
+		}
+		if err != nil {
+			return words, err
+		}
+		words = append(words, w)
 	}
-	return ret
 }
@@ -0,0 +1,181 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package shlextest runs text fixtures of inputs and their expected words
+// against shlex, so that cases can be added without writing Go code.
+//
+// A fixture file consists of cases separated by blank lines. Each case has
+// an "in:" line with the input, followed by one line per dialect with the
+// expected words. The input and words are written as Go string literals.
+// Lines starting with # are comments:
+//
+//	# Operators are only split off with WithOperators.
+//	in: "a|b 'c d'"
+//	default: "a|b" "c d"
+//	operators: "a" "|" "b" "c d"
+//
+// It also checks that quoting dialects round-trip, for use in fuzz tests; see
+// QuoteDialect.
+package shlextest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+// Fixture is a single case of a fixture file.
+type Fixture struct {
+	// Name identifies the case as "file:line".
+	Name string
+
+	// Input is the line to split.
+	Input string
+
+	// Want maps dialect names to the expected words.
+	Want map[string][]string
+}
+
+// ParseFixtures reads fixtures from r. name is used in the names of the
+// fixtures and in errors.
+func ParseFixtures(r io.Reader, name string) ([]Fixture, error) {
+	var fixtures []Fixture
+	var cur *Fixture
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		switch {
+		case len(line) == 0:
+			cur = nil
+			continue
+		case strings.HasPrefix(line, "#"):
+			continue
+		}
+
+		i := strings.IndexByte(line, ':')
+		if i < 0 {
+			return nil, fmt.Errorf("%s:%d: missing colon", name, n)
+		}
+		key, rest := line[:i], strings.TrimSpace(line[i+1:])
+		words, err := parseStrings(rest)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", name, n, err)
+		}
+
+		if key == "in" {
+			if cur != nil || len(words) != 1 {
+				return nil, fmt.Errorf("%s:%d: want a single input starting a case", name, n)
+			}
+			fixtures = append(fixtures, Fixture{
+				Name:  fmt.Sprintf("%s:%d", name, n),
+				Input: words[0],
+				Want:  make(map[string][]string),
+			})
+			cur = &fixtures[len(fixtures)-1]
+			continue
+		}
+		if cur == nil {
+			return nil, fmt.Errorf("%s:%d: dialect %q before input", name, n, key)
+		}
+		if _, ok := cur.Want[key]; ok {
+			return nil, fmt.Errorf("%s:%d: duplicate dialect %q", name, n, key)
+		}
+		cur.Want[key] = words
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return fixtures, nil
+}
+
+// parseStrings parses a blank-separated list of Go string literals.
+func parseStrings(s string) ([]string, error) {
+	words := []string{}
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if len(s) == 0 {
+			return words, nil
+		}
+		n := literalLen(s)
+		word, err := strconv.Unquote(s[:n])
+		if err != nil {
+			return nil, fmt.Errorf("bad string literal at %q", s)
+		}
+		words = append(words, word)
+		s = s[n:]
+	}
+}
+
+// literalLen returns the length of the Go string literal at the start of s,
+// or of the whole of s if it is not terminated.
+func literalLen(s string) int {
+	switch s[0] {
+	case '"':
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '"':
+				return i + 1
+			}
+		}
+	case '`':
+		if i := strings.IndexByte(s[1:], '`'); i >= 0 {
+			return i + 2
+		}
+	}
+	return len(s)
+}
+
+// LoadFixtures reads the fixtures of all files matching pattern, as for
+// filepath.Glob, e.g. "testdata/*.txt".
+func LoadFixtures(pattern string) ([]Fixture, error) {
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	var fixtures []Fixture
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		fs, err := ParseFixtures(f, file)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		fixtures = append(fixtures, fs...)
+	}
+	return fixtures, nil
+}
+
+// RunFixtures runs each fixture as a subtest of t, splitting its input with
+// the options of each dialect it lists and comparing the words. A dialect
+// missing from dialects is an error, so that misspelled names are noticed.
+func RunFixtures(t *testing.T, fixtures []Fixture, dialects map[string][]shlex.Option) {
+	for _, f := range fixtures {
+		f := f
+		t.Run(f.Name, func(t *testing.T) {
+			for dialect, want := range f.Want {
+				opts, ok := dialects[dialect]
+				if !ok {
+					t.Errorf("unknown dialect %q", dialect)
+					continue
+				}
+				if got := shlex.Split(f.Input, opts...); !reflect.DeepEqual(got, want) {
+					t.Errorf("%s: Split(%q) = %q, want %q", dialect, f.Input, got, want)
+				}
+			}
+		})
+	}
+}
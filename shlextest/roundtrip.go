@@ -0,0 +1,113 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlextest
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+// QuoteDialect pairs a way of joining an argv into a command line with the
+// way of splitting it back.
+type QuoteDialect struct {
+	Name string
+
+	// Join quotes and joins argv.
+	Join func(argv []string) string
+
+	// Split splits the line produced by Join.
+	Split func(line string) ([]string, error)
+}
+
+// Dialects returns the quoting dialects of shlex that round-trip: Join, and
+// JoinStyles with each Style but StyleANSIC, with and without WithOperators,
+// and JoinStyles with StyleANSIC split with the Bash profile.
+func Dialects() []QuoteDialect {
+	styles := []struct {
+		name  string
+		style shlex.Style
+	}{
+		{"auto", shlex.StyleAuto},
+		{"single", shlex.StyleSingle},
+		{"double", shlex.StyleDouble},
+		{"bare", shlex.StyleBare},
+	}
+	splits := []struct {
+		suffix string
+		opts   []shlex.Option
+	}{
+		{"", nil},
+		{"/operators", []shlex.Option{shlex.WithOperators(true)}},
+	}
+
+	var dialects []QuoteDialect
+	for _, sp := range splits {
+		split := lexSplit(sp.opts...)
+		dialects = append(dialects, QuoteDialect{Name: "join" + sp.suffix, Join: shlex.Join, Split: split})
+		for _, st := range styles {
+			dialects = append(dialects, QuoteDialect{Name: st.name + sp.suffix, Join: joinStyle(st.style), Split: split})
+		}
+	}
+	dialects = append(dialects, QuoteDialect{Name: "ansi-c/bash", Join: joinStyle(shlex.StyleANSIC), Split: lexSplit(shlex.Bash)})
+	return dialects
+}
+
+// lexSplit returns a function splitting a line with Lex and opts.
+func lexSplit(opts ...shlex.Option) func(line string) ([]string, error) {
+	return func(line string) ([]string, error) {
+		tokens, err := shlex.Lex(line, opts...)
+		argv := make([]string, 0, len(tokens))
+		for _, t := range tokens {
+			argv = append(argv, t.Value)
+		}
+		return argv, err
+	}
+}
+
+// joinStyle returns a function joining argv with JoinStyles, quoting every
+// argument with style.
+func joinStyle(style shlex.Style) func(argv []string) string {
+	return func(argv []string) string {
+		styles := make([]shlex.Style, len(argv))
+		for i := range styles {
+			styles[i] = style
+		}
+		return shlex.JoinStyles(argv, styles)
+	}
+}
+
+// CheckRoundTrip returns an error describing the mismatch if splitting d's
+// join of argv does not return argv.
+func CheckRoundTrip(d QuoteDialect, argv []string) error {
+	line := d.Join(argv)
+	got, err := d.Split(line)
+	if err != nil {
+		return fmt.Errorf("%s: Split(%q) = %v", d.Name, line, err)
+	}
+	if len(got) == 0 && len(argv) == 0 {
+		return nil
+	}
+	if !reflect.DeepEqual(got, argv) {
+		return fmt.Errorf("%s: Split(%q) = %q, want %q", d.Name, line, got, argv)
+	}
+	return nil
+}
+
+// FuzzArgv turns fuzzer input into an argv by splitting it at NUL bytes,
+// which no argument of a process can contain. The arguments are arbitrary
+// bytes otherwise, including invalid UTF-8.
+func FuzzArgv(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	var argv []string
+	for _, arg := range bytes.Split(data, []byte{0}) {
+		argv = append(argv, string(arg))
+	}
+	return argv
+}
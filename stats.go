@@ -0,0 +1,54 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+// LineStats summarizes a command line, as returned by Stats.
+type LineStats struct {
+	// Words is the number of words, not counting operators.
+	Words int
+
+	// QuotedWords is the number of words containing quotes or escapes.
+	QuotedWords int
+
+	// Expansions is the number of parameter and arithmetic expansions and
+	// command substitutions, including nested ones.
+	Expansions int
+
+	// Operators is the number of shell operators, such as | or &&.
+	Operators int
+
+	// MaxWordLen is the length in bytes of the longest word, with quotes
+	// and escapes removed.
+	MaxWordLen int
+
+	// Unterminated is set if the line ends within quotes or with a
+	// trailing backslash. The other counts cover the line up to there.
+	Unterminated bool
+}
+
+// Stats returns counts of the words, quoted words, expansions and operators
+// of line, for auditing executed commands in aggregate. Operators are split
+// off as by WithOperators.
+func Stats(line string) LineStats {
+	var st LineStats
+	words, err := splitWords(line, []Option{WithOperators(true)})
+	st.Unterminated = err != nil
+	for _, w := range words {
+		if w.operator {
+			st.Operators++
+			continue
+		}
+		st.Words++
+		if w.quoted {
+			st.QuotedWords++
+		}
+		if len(w.value) > st.MaxWordLen {
+			st.MaxWordLen = len(w.value)
+		}
+	}
+	exps, _ := scanExpansions(line)
+	st.Expansions = len(exps)
+	return st
+}
@@ -0,0 +1,48 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestParseAlias(t *testing.T) {
+	for i, tt := range []struct {
+		in        string
+		wantName  string
+		wantValue string
+		wantErr   error
+	}{
+		{in: "alias ll='ls -la'", wantName: "ll", wantValue: "ls -la"},
+		{in: `alias say='echo '\''hi'\'''`, wantName: "say", wantValue: "echo 'hi'"},
+		{in: `alias g="git \"log\" --oneline"`, wantName: "g", wantValue: `git "log" --oneline`},
+		{in: `alias ..='cd ..' # up`, wantName: "..", wantValue: "cd .."},
+		{in: "alias empty=", wantName: "empty", wantValue: ""},
+		{in: "  alias  x=y  ", wantName: "x", wantValue: "y"},
+		{in: "export ll='ls -la'", wantErr: shlex.ErrNotAlias},
+		{in: "", wantErr: shlex.ErrNotAlias},
+		{in: "alias ll", wantErr: shlex.ErrInvalidAlias},
+		{in: "alias a=b c=d", wantErr: shlex.ErrInvalidAlias},
+		{in: "alias 'll=ls'", wantErr: shlex.ErrInvalidAlias},
+		{in: "alias =ls", wantErr: shlex.ErrInvalidAlias},
+		{in: "alias ll='ls -la", wantErr: shlex.ErrUnterminatedSingleQuote},
+		{in: `alias ll="ls -la`, wantErr: shlex.ErrUnterminatedDoubleQuote},
+		{in: `alias ll=ls\`, wantErr: shlex.ErrTrailingBackslash},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.in), func(t *testing.T) {
+			name, value, err := shlex.ParseAlias(tt.in)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseAlias = %v, want %v", err, tt.wantErr)
+			}
+			if name != tt.wantName || value != tt.wantValue {
+				t.Errorf("ParseAlias = (%q, %q), want (%q, %q)", name, value, tt.wantName, tt.wantValue)
+			}
+		})
+	}
+}
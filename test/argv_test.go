@@ -0,0 +1,121 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestValidateArgvFor(t *testing.T) {
+	big := strings.Repeat("x", 200<<10)
+	for i, tt := range []struct {
+		desc      string
+		goos      string
+		argv      []string
+		wantErr   error
+		wantIndex int
+	}{
+		{
+			desc: "valid",
+			goos: "linux",
+			argv: []string{"echo", "hello world", ""},
+		},
+		{
+			desc:      "empty",
+			goos:      "linux",
+			argv:      nil,
+			wantErr:   shlex.ErrEmptyCommand,
+			wantIndex: -1,
+		},
+		{
+			desc:      "NUL",
+			goos:      "plan9",
+			argv:      []string{"echo", "a", "b\x00c"},
+			wantErr:   shlex.ErrNUL,
+			wantIndex: 2,
+		},
+		{
+			desc:      "linux argument",
+			goos:      "linux",
+			argv:      []string{"echo", big},
+			wantErr:   shlex.ErrArgTooLong,
+			wantIndex: 1,
+		},
+		{
+			desc: "linux argument at limit",
+			goos: "linux",
+			argv: []string{"echo", big[:128<<10-1]},
+		},
+		{
+			desc:      "linux total",
+			goos:      "linux",
+			argv:      append([]string{"echo"}, strings.Fields(strings.Repeat(big[:100<<10]+" ", 21))...),
+			wantErr:   shlex.ErrArgvTooLong,
+			wantIndex: 21,
+		},
+		{
+			desc: "darwin argument",
+			goos: "darwin",
+			argv: []string{"echo", big},
+		},
+		{
+			desc:      "freebsd total",
+			goos:      "freebsd",
+			argv:      []string{"echo", big, big},
+			wantErr:   shlex.ErrArgvTooLong,
+			wantIndex: 2,
+		},
+		{
+			desc:      "windows",
+			goos:      "windows",
+			argv:      []string{"echo", big[:32762]},
+			wantErr:   shlex.ErrArgvTooLong,
+			wantIndex: -1,
+		},
+		{
+			desc: "windows at limit",
+			goos: "windows",
+			argv: []string{"echo", big[:32761]},
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			err := shlex.ValidateArgvFor(tt.goos, tt.argv)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ValidateArgvFor = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+			var ae *shlex.ArgvError
+			if !errors.As(err, &ae) {
+				t.Fatalf("ValidateArgvFor = %T, want *ArgvError", err)
+			}
+			if ae.Index != tt.wantIndex {
+				t.Errorf("ValidateArgvFor = %v, want index %d", err, tt.wantIndex)
+			}
+		})
+	}
+}
+
+func TestArgvError(t *testing.T) {
+	for i, tt := range []struct {
+		err  *shlex.ArgvError
+		want string
+	}{
+		{&shlex.ArgvError{Index: 2, Err: shlex.ErrNUL}, "argument 2: string contains NUL byte"},
+		{&shlex.ArgvError{Index: -1, Err: shlex.ErrArgvTooLong}, "argv: argument list too long"},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.want), func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Errorf("Error = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
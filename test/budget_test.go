@@ -0,0 +1,159 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestSplitBudget(t *testing.T) {
+	for i, tt := range []struct {
+		desc    string
+		in      string
+		budget  shlex.Budget
+		opts    []shlex.Option
+		want    []string
+		wantErr *shlex.BudgetError
+	}{
+		{
+			desc: "unlimited",
+			in:   "stuff 'more stuff'",
+			want: []string{"stuff", "more stuff"},
+		},
+		{
+			desc:   "within budget",
+			in:     "a b c",
+			budget: shlex.Budget{MaxBytes: 5, MaxTokens: 3},
+			want:   []string{"a", "b", "c"},
+		},
+		{
+			desc:    "too many bytes",
+			in:      "a b c",
+			budget:  shlex.Budget{MaxBytes: 4},
+			wantErr: &shlex.BudgetError{Limit: shlex.ByteLimit, Max: 4, Offset: 4},
+		},
+		{
+			desc:    "too many tokens",
+			in:      "a b 'c d' e",
+			budget:  shlex.Budget{MaxTokens: 2},
+			wantErr: &shlex.BudgetError{Limit: shlex.TokenLimit, Max: 2, Offset: 4},
+		},
+		{
+			desc:    "too many bytes in quotes",
+			in:      "'" + strings.Repeat("x", 100),
+			budget:  shlex.Budget{MaxBytes: 10},
+			wantErr: &shlex.BudgetError{Limit: shlex.ByteLimit, Max: 10, Offset: 10},
+		},
+		{
+			desc:    "byte budget with token option",
+			in:      "a b c",
+			budget:  shlex.Budget{MaxBytes: 10},
+			opts:    []shlex.Option{shlex.WithMaxTokens(2)},
+			wantErr: &shlex.BudgetError{Limit: shlex.TokenLimit, Max: 2, Offset: 4},
+		},
+		{
+			desc:    "token budget with token length option",
+			in:      "a bcdefg",
+			budget:  shlex.Budget{MaxTokens: 3},
+			opts:    []shlex.Option{shlex.WithMaxTokenLen(5)},
+			wantErr: &shlex.BudgetError{Limit: shlex.TokenLenLimit, Max: 5, Offset: 2},
+		},
+		{
+			desc:   "budget overrides option",
+			in:     "a b c",
+			budget: shlex.Budget{MaxTokens: 3},
+			opts:   []shlex.Option{shlex.WithMaxTokens(2)},
+			want:   []string{"a", "b", "c"},
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			got, err := shlex.SplitBudget(tt.in, tt.budget, tt.opts...)
+			if tt.wantErr != nil {
+				var be *shlex.BudgetError
+				if !errors.As(err, &be) || !reflect.DeepEqual(be, tt.wantErr) {
+					t.Fatalf("SplitBudget = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SplitBudget = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitBudget = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitErrLimits(t *testing.T) {
+	for i, tt := range []struct {
+		desc    string
+		in      string
+		opts    []shlex.Option
+		want    []string
+		wantErr *shlex.BudgetError
+	}{
+		{
+			desc: "within limits",
+			in:   "abc 'd e'",
+			opts: []shlex.Option{shlex.WithMaxTokens(2), shlex.WithMaxTokenLen(5)},
+			want: []string{"abc", "d e"},
+		},
+		{
+			desc:    "too many tokens",
+			in:      "a b c",
+			opts:    []shlex.Option{shlex.WithMaxTokens(2)},
+			wantErr: &shlex.BudgetError{Limit: shlex.TokenLimit, Max: 2, Offset: 4},
+		},
+		{
+			desc:    "token too long",
+			in:      "a 'b c d'",
+			opts:    []shlex.Option{shlex.WithMaxTokenLen(6)},
+			wantErr: &shlex.BudgetError{Limit: shlex.TokenLenLimit, Max: 6, Offset: 2},
+		},
+		{
+			desc:    "last token too long",
+			in:      "a bcdefg",
+			opts:    []shlex.Option{shlex.WithMaxTokenLen(5)},
+			wantErr: &shlex.BudgetError{Limit: shlex.TokenLenLimit, Max: 5, Offset: 2},
+		},
+		{
+			desc:    "unterminated token too long",
+			in:      "'" + strings.Repeat("x", 100),
+			opts:    []shlex.Option{shlex.WithMaxTokenLen(10)},
+			wantErr: &shlex.BudgetError{Limit: shlex.TokenLenLimit, Max: 10, Offset: 0},
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			got, err := shlex.SplitErr(tt.in, tt.opts...)
+			if tt.wantErr != nil {
+				var be *shlex.BudgetError
+				if !errors.As(err, &be) || !reflect.DeepEqual(be, tt.wantErr) {
+					t.Fatalf("SplitErr = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SplitErr = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitErr = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBudgetErrorString(t *testing.T) {
+	_, err := shlex.SplitErr("abcdef", shlex.WithMaxTokenLen(4))
+	if want := "1:1: offset 0: exceeded limit of 4 bytes per token"; err == nil || err.Error() != want {
+		t.Errorf("SplitErr = %v, want %s", err, want)
+	}
+}
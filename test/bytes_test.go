@@ -0,0 +1,48 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestSplitBytes(t *testing.T) {
+	for i, tt := range []struct {
+		in   string
+		opts []shlex.Option
+	}{
+		{in: ""},
+		{in: `echo 'a b' "" c\ d`},
+		{in: "こんにちは　世界 # comment"},
+		{in: `unterminated "quote`},
+		{in: "a&&b", opts: []shlex.Option{shlex.WithOperators(true)}},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.in), func(t *testing.T) {
+			in := []byte(tt.in)
+			got := shlex.SplitBytes(in, tt.opts...)
+
+			want := shlex.Split(tt.in, tt.opts...)
+			gotStrings := []string{}
+			for _, w := range got {
+				gotStrings = append(gotStrings, string(w))
+			}
+			if !reflect.DeepEqual(gotStrings, want) {
+				t.Errorf("SplitBytes = %q, want %q", gotStrings, want)
+			}
+
+			// Appending to a word must not clobber the next one.
+			if len(got) > 1 {
+				_ = append(got[0], 'x')
+				if string(got[1]) != want[1] {
+					t.Errorf("append to word 0 changed word 1 to %q", got[1])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestMetacharacters(t *testing.T) {
+	for _, r := range shlex.Metacharacters {
+		if !shlex.IsMetacharacter(r) {
+			t.Errorf("IsMetacharacter(%q) = false", r)
+		}
+		if shlex.IsSafe(r) || !shlex.NeedsQuoting(string(r)) {
+			t.Errorf("%q is safe", r)
+		}
+		// Split agrees.
+		in := "a" + string(r) + "b"
+		want := []string{"a", string(r), "b"}
+		if got := shlex.Split(in, shlex.WithOperators(true)); !reflect.DeepEqual(got, want) {
+			t.Errorf("Split(%q) = %q, want %q", in, got, want)
+		}
+	}
+	for _, r := range "abc$'\"\\ \t*" {
+		if shlex.IsMetacharacter(r) {
+			t.Errorf("IsMetacharacter(%q) = true", r)
+		}
+	}
+}
+
+func TestIsBlank(t *testing.T) {
+	for i, tt := range []struct {
+		r    rune
+		want bool
+	}{
+		{' ', true},
+		{'\t', true},
+		{'\n', true},
+		{'\u3000', true},
+		{'\u00a0', true},
+		{'a', false},
+		{'\u200b', false},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %q", i, tt.r), func(t *testing.T) {
+			if got := shlex.IsBlank(tt.r); got != tt.want {
+				t.Errorf("IsBlank = %t, want %t", got, tt.want)
+			}
+			// Split agrees.
+			split := len(shlex.Split("a"+string(tt.r)+"b")) == 2
+			if split != tt.want {
+				t.Errorf("Split splits at %q: %t, want %t", tt.r, split, tt.want)
+			}
+		})
+	}
+}
+
+func TestNeedsQuoting(t *testing.T) {
+	for i, tt := range []struct {
+		in   string
+		want bool
+	}{
+		{"", true},
+		{"abc-1.2_x/y:z,@%+=", false},
+		{"a b", true},
+		{"~", true},
+		{"é", true},
+		{"$x", true},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %q", i, tt.in), func(t *testing.T) {
+			if got := shlex.NeedsQuoting(tt.in); got != tt.want {
+				t.Errorf("NeedsQuoting = %t, want %t", got, tt.want)
+			}
+			if got := shlex.Quote(tt.in) != tt.in; got != tt.want {
+				t.Errorf("Quote changes %q: %t, want %t", tt.in, got, tt.want)
+			}
+			safe := len(tt.in) > 0 && strings.IndexFunc(tt.in, func(r rune) bool { return !shlex.IsSafe(r) }) < 0
+			if safe == tt.want {
+				t.Errorf("IsSafe of all runes = %t, want %t", safe, !tt.want)
+			}
+		})
+	}
+}
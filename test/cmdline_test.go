@@ -0,0 +1,58 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestParseCmdline(t *testing.T) {
+	for i, tt := range []struct {
+		desc    string
+		in      string
+		want    []string
+		wantNUL bool
+	}{
+		{
+			desc:    "proc cmdline",
+			in:      "/bin/sh\x00-c\x00echo 'a b'\x00",
+			want:    []string{"/bin/sh", "-c", "echo 'a b'"},
+			wantNUL: true,
+		},
+		{
+			desc:    "empty arguments",
+			in:      "prog\x00\x00x\x00",
+			want:    []string{"prog", "", "x"},
+			wantNUL: true,
+		},
+		{
+			desc:    "no trailing NUL",
+			in:      "prog\x00x",
+			want:    []string{"prog", "x"},
+			wantNUL: true,
+		},
+		{
+			desc: "ps args",
+			in:   "/usr/bin/python3 -m http.server  8000",
+			want: []string{"/usr/bin/python3", "-m", "http.server", "8000"},
+		},
+		{
+			desc: "empty",
+			in:   "",
+			want: []string{},
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			got, nul := shlex.ParseCmdline(tt.in)
+			if !reflect.DeepEqual(got, tt.want) || nul != tt.wantNUL {
+				t.Errorf("ParseCmdline = %#v, %v, want %#v, %v", got, nul, tt.want, tt.wantNUL)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestCommandReader(t *testing.T) {
+	for i, tt := range []struct {
+		desc    string
+		in      string
+		opts    []shlex.Option
+		want    [][]string
+		wantErr error
+		wantPos shlex.Position
+	}{
+		{
+			desc: "commands",
+			in:   "echo hi\n\n# comment\nls -l 'a b'\n",
+			want: [][]string{{"echo", "hi"}, {"ls", "-l", "a b"}},
+		},
+		{
+			desc: "no final newline",
+			in:   "true\nfalse",
+			want: [][]string{{"true"}, {"false"}},
+		},
+		{
+			desc: "newline within quotes",
+			in:   "printf 'a\nb'\necho \"c\n\nd\"\n",
+			want: [][]string{{"printf", "a\nb"}, {"echo", "c\n\nd"}},
+		},
+		{
+			desc: "crlf",
+			in:   "echo a\r\necho b\r\n",
+			opts: []shlex.Option{shlex.WithStripCR(true)},
+			want: [][]string{{"echo", "a"}, {"echo", "b"}},
+		},
+		{
+			desc:    "unterminated quote",
+			in:      "true\n\necho 'a\nb\n",
+			opts:    []shlex.Option{shlex.WithSource("jobs")},
+			want:    [][]string{{"true"}},
+			wantErr: shlex.ErrUnterminatedSingleQuote,
+			wantPos: shlex.Position{Source: "jobs", Offset: 11, Line: 3, Column: 6},
+		},
+		{
+			desc:    "trailing backslash",
+			in:      "true\necho 'a\nb' \\\nc\n",
+			want:    [][]string{{"true"}},
+			wantErr: shlex.ErrTrailingBackslash,
+			wantPos: shlex.Position{Offset: 16, Line: 3, Column: 4},
+		},
+		{
+			desc:    "utf16",
+			in:      "echo 🎉\necho \"x\n",
+			opts:    []shlex.Option{shlex.WithUTF16Positions(true)},
+			want:    [][]string{{"echo", "🎉"}},
+			wantErr: shlex.ErrUnterminatedDoubleQuote,
+			wantPos: shlex.Position{Offset: 15, Line: 2, Column: 6, UTF16Offset: 13, UTF16Column: 6},
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			got, err := shlex.NewCommandReader(strings.NewReader(tt.in), tt.opts...).ReadAll()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReadAll = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadAll = %#v, want %#v", got, tt.want)
+			}
+			if err == nil {
+				return
+			}
+			var pe *shlex.ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("ReadAll = %T, want *ParseError", err)
+			}
+			if pe.Pos != tt.wantPos {
+				t.Errorf("ReadAll error at %#v, want %#v", pe.Pos, tt.wantPos)
+			}
+		})
+	}
+}
+
+func TestCommandReaderLimit(t *testing.T) {
+	// The reader fails after the command over the limit, which must not
+	// be read on from as if it were in quotes.
+	errFailed := errors.New("failed")
+	in := io.MultiReader(strings.NewReader("true\na b c\nd\n"), iotest.ErrReader(errFailed))
+	r := shlex.NewCommandReader(in, shlex.WithMaxTokens(2))
+	if argv, err := r.Read(); err != nil || !reflect.DeepEqual(argv, []string{"true"}) {
+		t.Fatalf("Read = %#v, %v, want [true]", argv, err)
+	}
+	_, err := r.Read()
+	var be *shlex.BudgetError
+	var pe *shlex.ParseError
+	if !errors.As(err, &be) || be.Limit != shlex.TokenLimit || !errors.As(err, &pe) || pe.Pos.Line != 2 {
+		t.Errorf("Read = %v, want *ParseError for the token limit on line 2", err)
+	}
+}
+
+func TestCommandWriter(t *testing.T) {
+	cmds := [][]string{
+		{"echo", "hi"},
+		{"printf", "%s\n", "a\nb", ""},
+		{"sh", "-c", "echo 'it''s' # not a comment"},
+		{"#", "\r", `\`, `"`},
+	}
+
+	var b strings.Builder
+	w := shlex.NewCommandWriter(&b)
+	for _, argv := range cmds {
+		if err := w.Write(argv); err != nil {
+			t.Fatalf("Write(%q) = %v", argv, err)
+		}
+	}
+	got, err := shlex.NewCommandReader(strings.NewReader(b.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll = %v", err)
+	}
+	if !reflect.DeepEqual(got, cmds) {
+		t.Errorf("ReadAll = %#v, want %#v", got, cmds)
+	}
+
+	for i, tt := range []struct {
+		argv    []string
+		wantErr error
+	}{
+		{argv: nil, wantErr: shlex.ErrEmptyCommand},
+		{argv: []string{"a\x00b"}, wantErr: shlex.ErrNUL},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %v", i, tt.wantErr), func(t *testing.T) {
+			if err := w.Write(tt.argv); err != tt.wantErr {
+				t.Errorf("Write = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestFormatColumns(t *testing.T) {
+	argv := []string{"docker", "run", "--rm", "-v", "/a b:/c", "alpine", "echo", "hello world"}
+	for i, tt := range []struct {
+		desc  string
+		argv  []string
+		width int
+		want  []string
+	}{
+		{
+			desc:  "wrapped",
+			argv:  argv,
+			width: 20,
+			want: []string{
+				`docker run --rm -v \`,
+				`  '/a b:/c' alpine \`,
+				`  echo 'hello world'`,
+			},
+		},
+		{
+			desc:  "unlimited",
+			argv:  argv,
+			width: 0,
+			want:  []string{`docker run --rm -v '/a b:/c' alpine echo 'hello world'`},
+		},
+		{
+			desc:  "too long",
+			argv:  []string{"x", "a very long argument", "y"},
+			width: 10,
+			want: []string{
+				`x \`,
+				`  'a very long argument' \`,
+				`  y`,
+			},
+		},
+		{
+			desc:  "empty",
+			width: 10,
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			got := shlex.FormatColumns(tt.argv, tt.width)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FormatColumns = %#v, want %#v", got, tt.want)
+			}
+			joined := strings.ReplaceAll(strings.Join(got, "\n"), " \\\n", " ")
+			if args := shlex.Split(joined); len(tt.argv) > 0 && !reflect.DeepEqual(args, tt.argv) {
+				t.Errorf("Split of FormatColumns = %#v, want %#v", args, tt.argv)
+			}
+		})
+	}
+}
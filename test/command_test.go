@@ -0,0 +1,202 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestEnvPrefix(t *testing.T) {
+	for i, tt := range []struct {
+		in       string
+		wantEnv  []string
+		wantArgs []string
+	}{
+		{
+			in:       "make all",
+			wantArgs: []string{"make", "all"},
+		},
+		{
+			in:       `FOO=1 BAR="a b" make V=1`,
+			wantEnv:  []string{"FOO=1", "BAR=a b"},
+			wantArgs: []string{"make", "V=1"},
+		},
+		{
+			in:       `'FOO=1' make`,
+			wantArgs: []string{"FOO=1", "make"},
+		},
+		{
+			in:       `FOO\=1 ""BAR=2 make`,
+			wantArgs: []string{"FOO=1", "BAR=2", "make"},
+		},
+		{
+			in:       "1FOO=1 =x make",
+			wantArgs: []string{"1FOO=1", "=x", "make"},
+		},
+		{
+			in:      "FOO= BAR=''",
+			wantEnv: []string{"FOO=", "BAR="},
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.in), func(t *testing.T) {
+			env, args := shlex.EnvPrefix(tt.in)
+			if !reflect.DeepEqual(env, tt.wantEnv) || !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("EnvPrefix = (%#v, %#v), want (%#v, %#v)", env, args, tt.wantEnv, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestEnvPrefixSpans(t *testing.T) {
+	for i, tt := range []struct {
+		in        string
+		wantEnv   []shlex.Assignment
+		wantArgs  []string
+		wantStart int
+		wantEnd   int
+	}{
+		{
+			in:        "  make all  ",
+			wantArgs:  []string{"make", "all"},
+			wantStart: 2,
+			wantEnd:   10,
+		},
+		{
+			in: `FOO=1  BAR="a b" make 'V=1' # comment`,
+			wantEnv: []shlex.Assignment{
+				{Name: "FOO", Value: "1", Start: 0, End: 5},
+				{Name: "BAR", Value: "a b", Start: 7, End: 16},
+			},
+			wantArgs:  []string{"make", "V=1"},
+			wantStart: 17,
+			wantEnd:   27,
+		},
+		{
+			in: "B=2 A=1",
+			wantEnv: []shlex.Assignment{
+				{Name: "B", Value: "2", Start: 0, End: 3},
+				{Name: "A", Value: "1", Start: 4, End: 7},
+			},
+			wantStart: -1,
+			wantEnd:   -1,
+		},
+		{
+			in:        "",
+			wantStart: -1,
+			wantEnd:   -1,
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.in), func(t *testing.T) {
+			env, args, start, end := shlex.EnvPrefixSpans(tt.in)
+			if !reflect.DeepEqual(env, tt.wantEnv) || !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("EnvPrefixSpans = (%#v, %#v), want (%#v, %#v)", env, args, tt.wantEnv, tt.wantArgs)
+			}
+			if start != tt.wantStart || end != tt.wantEnd {
+				t.Errorf("EnvPrefixSpans command at [%d, %d), want [%d, %d)", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestCommandName(t *testing.T) {
+	for i, tt := range []struct {
+		in   string
+		want string
+	}{
+		{in: "ls -la", want: "ls"},
+		{in: "'/usr/bin/python3' script.py", want: "python3"},
+		{in: `LANG=C PYTHONPATH="a b" /usr/bin/python3 -c x`, want: "python3"},
+		{in: `"/opt/my tools/run"`, want: "run"},
+		{in: "FOO=1", want: ""},
+		{in: "", want: ""},
+		{in: "'' x", want: ""},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.in), func(t *testing.T) {
+			if got := shlex.CommandName(tt.in); got != tt.want {
+				t.Errorf("CommandName = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSingleWord(t *testing.T) {
+	for i, tt := range []struct {
+		in   string
+		want bool
+	}{
+		{in: "/usr/bin/python3", want: true},
+		{in: `"/opt/my tools/run"`, want: true},
+		{in: `/opt/my\ tools/run`, want: true},
+		{in: "''", want: true},
+		{in: "'a|b'", want: true},
+		{in: "", want: false},
+		{in: "a b", want: false},
+		{in: " a", want: false},
+		{in: "a ", want: false},
+		{in: "a;b", want: false},
+		{in: "a|b", want: false},
+		{in: "a>b", want: false},
+		{in: "$(ls)", want: false},
+		{in: "a #b", want: false},
+		{in: "#a", want: false},
+		{in: "'a", want: false},
+		{in: `a\`, want: false},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.in), func(t *testing.T) {
+			if got := shlex.IsSingleWord(tt.in); got != tt.want {
+				t.Errorf("IsSingleWord(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequiresShell(t *testing.T) {
+	for i, tt := range []struct {
+		in   string
+		want bool
+	}{
+		{in: "", want: false},
+		{in: "ls -la 'my dir'", want: false},
+		{in: `grep -e "a|b" file # comment`, want: false},
+		{in: `echo '$HOME' \* "[x]" '~'`, want: false},
+		{in: "ls | wc -l", want: true},
+		{in: "make&&make install", want: true},
+		{in: "echo hi > out", want: true},
+		{in: "echo hi;", want: true},
+		{in: "echo $HOME", want: true},
+		{in: `echo "$(id)"`, want: true},
+		{in: "ls *.go", want: true},
+		{in: "ls file?", want: true},
+		{in: "echo {a,b}", want: true},
+		{in: "ls ~/src", want: true},
+		{in: "FOO=1 make", want: true},
+		{in: "cd /tmp", want: true},
+		{in: "if true", want: true},
+		{in: "echo 'unterminated", want: true},
+		{in: "echo a\\ #`id`", want: true},
+		{in: `echo a\ # b`, want: false},
+		{in: `echo $'a\nb'`, want: true},
+		{in: `echo $"a"`, want: true},
+		{in: `echo '$"a"' "$'a'" a$`, want: false},
+		{in: "ls\nrm x", want: true},
+		{in: "ls # c\nrm x", want: true},
+		{in: "\n ls -l\n", want: false},
+		{in: "ls \\\nfoo", want: true},
+		{in: "echo \"a\\\nb\"", want: true},
+		{in: "echo a\rb", want: true},
+		{in: "echo a\u00a0b", want: true},
+		{in: "echo 'a\rb' # \u00a0", want: false},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.in), func(t *testing.T) {
+			if got := shlex.RequiresShell(tt.in); got != tt.want {
+				t.Errorf("RequiresShell(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
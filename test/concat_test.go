@@ -0,0 +1,74 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestConcat(t *testing.T) {
+	for i, tt := range []struct {
+		desc      string
+		fragments []string
+		want      string
+		wantArgs  []string
+		wantErr   error
+	}{
+		{
+			desc: "none",
+		},
+		{
+			desc:      "words",
+			fragments: []string{"ls -l", "", "'my dir'"},
+			want:      "ls -l 'my dir'",
+			wantArgs:  []string{"ls", "-l", "my dir"},
+		},
+		{
+			desc:      "escaped blank",
+			fragments: []string{`a\ `, "b"},
+			want:      `a\  b`,
+			wantArgs:  []string{"a ", "b"},
+		},
+		{
+			desc:      "last comment",
+			fragments: []string{"make", "# build it", ""},
+			want:      "make # build it",
+			wantArgs:  []string{"make"},
+		},
+		{
+			desc:      "open quote",
+			fragments: []string{"echo", `"a`, `b"`},
+			wantErr:   shlex.ErrUnterminatedDoubleQuote,
+		},
+		{
+			desc:      "trailing backslash",
+			fragments: []string{`echo \`, "x"},
+			wantErr:   shlex.ErrTrailingBackslash,
+		},
+		{
+			desc:      "comment",
+			fragments: []string{"make # build", "install"},
+			wantErr:   shlex.ErrTrailingComment,
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			got, err := shlex.Concat(tt.fragments...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Concat = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Concat = %q, want %q", got, tt.want)
+			}
+			if args := shlex.Split(got); len(tt.wantArgs) > 0 && !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Split(Concat) = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
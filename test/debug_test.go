@@ -0,0 +1,38 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestDumpTokens(t *testing.T) {
+	var b strings.Builder
+	if err := shlex.DumpTokens(&b, "ls 'a b'*|wc", shlex.WithOperators(true)); err != nil {
+		t.Fatalf("DumpTokens = %v", err)
+	}
+	want := `{"value":"ls","raw":"ls","kind":"word","start":0,"end":2,"line":1,"column":1,"segments":[{"kind":"literal","raw":"ls"}]}
+{"value":"a b*","raw":"'a b'*","kind":"word","start":3,"end":9,"line":1,"column":4,"segments":[{"kind":"quoted","raw":"'a b'"},{"kind":"glob","raw":"*"}]}
+{"value":"|","raw":"|","kind":"operator","start":9,"end":10,"line":1,"column":10,"segments":[{"kind":"literal","raw":"|"}]}
+{"value":"wc","raw":"wc","kind":"word","start":10,"end":12,"line":1,"column":11,"segments":[{"kind":"literal","raw":"wc"}]}
+`
+	if got := b.String(); got != want {
+		t.Errorf("DumpTokens =\n%s\nwant\n%s", got, want)
+	}
+
+	b.Reset()
+	err := shlex.DumpTokens(&b, `echo "oops`)
+	if !errors.Is(err, shlex.ErrUnterminatedDoubleQuote) {
+		t.Errorf("DumpTokens = %v, want %v", err, shlex.ErrUnterminatedDoubleQuote)
+	}
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(lines) != 3 || lines[2] != `{"error":"1:6: unterminated double quote"}` {
+		t.Errorf("DumpTokens = %q, want 2 tokens and an error", lines)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestCapabilities(t *testing.T) {
+	for i, tt := range []struct {
+		d    shlex.Dialect
+		want []shlex.Feature
+	}{
+		{
+			d:    shlex.DialectPOSIX,
+			want: []shlex.Feature{shlex.FeatureCommandSubstitution, shlex.FeatureArithmeticExpansion, shlex.FeatureHeredoc},
+		},
+		{
+			d: shlex.DialectBash,
+			want: []shlex.Feature{
+				shlex.FeatureCommandSubstitution, shlex.FeatureArithmeticExpansion, shlex.FeatureHeredoc,
+				shlex.FeatureHereString, shlex.FeatureProcessSubstitution, shlex.FeatureANSICQuoting,
+				shlex.FeatureLocaleQuoting, shlex.FeatureBraceExpansion, shlex.FeatureArrays,
+				shlex.FeatureExtendedTest, shlex.FeatureRedirectAll, shlex.FeaturePipeAll,
+			},
+		},
+		{
+			d: shlex.DialectFish,
+			want: []shlex.Feature{
+				shlex.FeatureCommandSubstitution, shlex.FeatureBraceExpansion, shlex.FeatureArrays,
+				shlex.FeatureRedirectAll,
+			},
+		},
+		{d: shlex.Dialect(100)},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.d), func(t *testing.T) {
+			got := shlex.Capabilities(tt.d)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Capabilities = %v, want %v", got, tt.want)
+			}
+			for _, f := range got {
+				if !tt.d.Supports(f) {
+					t.Errorf("Supports(%v) = false", f)
+				}
+			}
+		})
+	}
+}
+
+func TestDialectSupports(t *testing.T) {
+	for _, tt := range []struct {
+		d    shlex.Dialect
+		f    shlex.Feature
+		want bool
+	}{
+		{shlex.DialectPOSIX, shlex.FeatureHeredoc, true},
+		{shlex.DialectPOSIX, shlex.FeatureProcessSubstitution, false},
+		{shlex.DialectKsh, shlex.FeaturePipeAll, false},
+		{shlex.DialectZsh, shlex.FeatureLocaleQuoting, false},
+		{shlex.DialectFish, shlex.FeatureHeredoc, false},
+		{shlex.DialectBash, shlex.Feature(200), false},
+	} {
+		if got := tt.d.Supports(tt.f); got != tt.want {
+			t.Errorf("%v.Supports(%v) = %v, want %v", tt.d, tt.f, got, tt.want)
+		}
+	}
+}
+
+func TestDialectString(t *testing.T) {
+	for _, tt := range []struct {
+		s    fmt.Stringer
+		want string
+	}{
+		{shlex.DialectPOSIX, "posix"},
+		{shlex.DialectFish, "fish"},
+		{shlex.Dialect(9), "Dialect(9)"},
+		{shlex.FeatureHereString, "here-string"},
+		{shlex.Feature(99), "Feature(99)"},
+	} {
+		if got := tt.s.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
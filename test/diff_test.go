@@ -0,0 +1,98 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestDiffArgv(t *testing.T) {
+	for i, tt := range []struct {
+		desc string
+		a, b []string
+		want []shlex.Edit
+	}{
+		{
+			desc: "equal",
+			a:    []string{"ls", "-l"},
+			b:    []string{"ls", "-l"},
+		},
+		{
+			desc: "insert",
+			a:    []string{"ls", "/tmp"},
+			b:    []string{"ls", "-l", "/tmp"},
+			want: []shlex.Edit{
+				{Op: shlex.EditInsert, AIndex: 1, BIndex: 1, New: "-l"},
+			},
+		},
+		{
+			desc: "delete",
+			a:    []string{"rm", "-rf", "dir"},
+			b:    []string{"rm", "dir"},
+			want: []shlex.Edit{
+				{Op: shlex.EditDelete, AIndex: 1, BIndex: 1, Old: "-rf"},
+			},
+		},
+		{
+			desc: "replace",
+			a:    []string{"cp", "a", "b"},
+			b:    []string{"cp", "a", "c d"},
+			want: []shlex.Edit{
+				{Op: shlex.EditReplace, AIndex: 2, BIndex: 2, Old: "b", New: "c d"},
+			},
+		},
+		{
+			desc: "replace and insert",
+			a:    []string{"go", "test", "./..."},
+			b:    []string{"go", "vet", "-v", "./..."},
+			want: []shlex.Edit{
+				{Op: shlex.EditReplace, AIndex: 1, BIndex: 1, Old: "test", New: "vet"},
+				{Op: shlex.EditInsert, AIndex: 2, BIndex: 2, New: "-v"},
+			},
+		},
+		{
+			desc: "from empty",
+			b:    []string{"true"},
+			want: []shlex.Edit{
+				{Op: shlex.EditInsert, AIndex: 0, BIndex: 0, New: "true"},
+			},
+		},
+		{
+			desc: "to empty",
+			a:    []string{"a", "b"},
+			want: []shlex.Edit{
+				{Op: shlex.EditDelete, AIndex: 0, BIndex: 0, Old: "a"},
+				{Op: shlex.EditDelete, AIndex: 1, BIndex: 0, Old: "b"},
+			},
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			if got := shlex.DiffArgv(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffArgv(%q, %q) = %+v, want %+v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditString(t *testing.T) {
+	for i, tt := range []struct {
+		edit shlex.Edit
+		want string
+	}{
+		{edit: shlex.Edit{Op: shlex.EditInsert, New: "-v"}, want: "+-v"},
+		{edit: shlex.Edit{Op: shlex.EditDelete, Old: "a b"}, want: "-'a b'"},
+		{edit: shlex.Edit{Op: shlex.EditReplace, Old: "", New: "it's"}, want: `-'' +"it's"`},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.want), func(t *testing.T) {
+			if got := tt.edit.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestSanitizeForDisplay(t *testing.T) {
+	for i, tt := range []struct {
+		desc string
+		in   []string
+		want []string
+	}{
+		{desc: "plain", in: []string{"ls", "-l", "héllo wörld", "😀", ""}, want: []string{"ls", "-l", "héllo wörld", "😀", ""}},
+		{desc: "controls", in: []string{"a\nb\tc\r", "\x1b[31mred\x1b[0m", "\x00\x7f"}, want: []string{`a\nb\tc\r`, `\x1b[31mred\x1b[0m`, `\x00\x7f`}},
+		{desc: "C1 and separators", in: []string{"a\u0085b\u2028c"}, want: []string{`a\u0085b\u2028c`}},
+		{desc: "format", in: []string{"evil\u202egnp.exe", "zero\u200bwidth", "tag\U000e0041"}, want: []string{`evil\u202egnp.exe`, `zero\u200bwidth`, `tag\U000e0041`}},
+		{desc: "invalid UTF-8", in: []string{"a\xffb\xc3"}, want: []string{`a\xffb\xc3`}},
+		{desc: "backslash", in: []string{`C:\dir`}, want: []string{`C:\dir`}},
+		{desc: "long", in: []string{strings.Repeat("x", 300)}, want: []string{strings.Repeat("x", 255) + "…"}},
+		{desc: "limit", in: []string{strings.Repeat("x", 256)}, want: []string{strings.Repeat("x", 256)}},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			if got := shlex.SanitizeForDisplay(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SanitizeForDisplay = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeForDisplayLimits(t *testing.T) {
+	for i, tt := range []struct {
+		in     []string
+		limits shlex.DisplayLimits
+		want   []string
+	}{
+		{in: []string{"abcdef"}, limits: shlex.DisplayLimits{MaxArgLen: 4}, want: []string{"abc…"}},
+		{in: []string{"abcd"}, limits: shlex.DisplayLimits{MaxArgLen: 4}, want: []string{"abcd"}},
+		{in: []string{"ab\ncd"}, limits: shlex.DisplayLimits{MaxArgLen: 4}, want: []string{"ab…"}},
+		{in: []string{"a\nb"}, limits: shlex.DisplayLimits{MaxArgLen: 4}, want: []string{`a\nb`}},
+		{in: []string{"éééé", "ééééé"}, limits: shlex.DisplayLimits{MaxArgLen: 4}, want: []string{"éééé", "ééé…"}},
+		{in: []string{strings.Repeat("x", 1000)}, want: []string{strings.Repeat("x", 1000)}},
+		{in: []string{"a", "b", "c", "d", "e"}, limits: shlex.DisplayLimits{MaxArgs: 3}, want: []string{"a", "b", "… (3 more)"}},
+		{in: []string{"a", "b", "c"}, limits: shlex.DisplayLimits{MaxArgs: 3}, want: []string{"a", "b", "c"}},
+		{in: []string{"a", "b"}, limits: shlex.DisplayLimits{MaxArgs: 1}, want: []string{"… (2 more)"}},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %q", i, tt.in), func(t *testing.T) {
+			if got := shlex.SanitizeForDisplayLimits(tt.in, tt.limits); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SanitizeForDisplayLimits = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
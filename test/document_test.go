@@ -0,0 +1,60 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestCommandAt(t *testing.T) {
+	doc := "#!/bin/sh\n" + // 1
+		"echo one\n" + // 2
+		"docker run \\\n" + // 3
+		"  -it \\\n" + // 4
+		"  alpine\n" + // 5
+		"echo 'multi\n" + // 6
+		"line' # don't \\\n" + // 7
+		"ls \"x\\\"\n" + // 8
+		"y\"\n" + // 9
+		"echo a\\ #b \\\n" + // 10
+		"  c # d\n" // 11
+
+	for i, tt := range []struct {
+		line     int
+		want     []string
+		wantSpan string
+	}{
+		{line: 1, want: []string{}, wantSpan: "#!/bin/sh"},
+		{line: 2, want: []string{"echo", "one"}, wantSpan: "echo one"},
+		{line: 3, want: []string{"docker", "run", "-it", "alpine"}, wantSpan: "docker run \\\n  -it \\\n  alpine"},
+		{line: 5, want: []string{"docker", "run", "-it", "alpine"}, wantSpan: "docker run \\\n  -it \\\n  alpine"},
+		{line: 7, want: []string{"echo", "multi\nline"}, wantSpan: "echo 'multi\nline' # don't \\"},
+		{line: 9, want: []string{"ls", "x\"\ny"}, wantSpan: "ls \"x\\\"\ny\""},
+		{line: 10, want: []string{"echo", "a #b", "c"}, wantSpan: "echo a\\ #b \\\n  c # d"},
+		{line: 12, want: []string{}, wantSpan: ""},
+		{line: 13},
+		{line: 0},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] line %d", i, tt.line), func(t *testing.T) {
+			argv, start, end := shlex.CommandAt(doc, tt.line)
+			if !reflect.DeepEqual(argv, tt.want) {
+				t.Errorf("CommandAt = %#v, want %#v", argv, tt.want)
+			}
+			if tt.want == nil {
+				if start != -1 || end != -1 {
+					t.Errorf("CommandAt span = [%d, %d), want [-1, -1)", start, end)
+				}
+				return
+			}
+			if span := doc[start:end]; span != tt.wantSpan {
+				t.Errorf("CommandAt span = %q, want %q", span, tt.wantSpan)
+			}
+		})
+	}
+}
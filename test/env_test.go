@@ -0,0 +1,61 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestFormatEnvAssignments(t *testing.T) {
+	for i, tt := range []struct {
+		desc    string
+		env     map[string]string
+		want    []string
+		wantEnv []string
+	}{
+		{
+			desc: "empty",
+			want: []string{},
+		},
+		{
+			desc:    "sorted and quoted",
+			env:     map[string]string{"PATH": "/bin:/usr/bin", "MSG": "it's here", "EMPTY": ""},
+			want:    []string{"EMPTY=''", `MSG="it's here"`, "PATH=/bin:/usr/bin"},
+			wantEnv: []string{"EMPTY=", "MSG=it's here", "PATH=/bin:/usr/bin"},
+		},
+		{
+			desc: "invalid name",
+			env:  map[string]string{"A-B": "x y"},
+			want: []string{"'A-B=x y'"},
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			got := shlex.FormatEnvAssignments(tt.env)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FormatEnvAssignments = %#v, want %#v", got, tt.want)
+			}
+			env, _ := shlex.EnvPrefix(strings.Join(got, " ") + " true")
+			if !reflect.DeepEqual(env, tt.wantEnv) {
+				t.Errorf("EnvPrefix = %#v, want %#v", env, tt.wantEnv)
+			}
+		})
+	}
+}
+
+func TestJoinEnv(t *testing.T) {
+	got := shlex.JoinEnv(map[string]string{"LANG": "C", "X": "a b"}, []string{"sort", "-k 2"})
+	want := "env LANG=C X='a b' sort '-k 2'"
+	if got != want {
+		t.Errorf("JoinEnv = %q, want %q", got, want)
+	}
+	if got, want := shlex.JoinEnv(nil, nil), "env"; got != want {
+		t.Errorf("JoinEnv = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"fmt"
+	"os/exec"
+	"reflect"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestFormatCommand(t *testing.T) {
+	for i, tt := range []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "ls", want: "ls"},
+		{name: "/usr/bin/git", args: []string{"commit", "-m", "it's done"}, want: `/usr/bin/git commit -m "it's done"`},
+		{name: "my prog", args: []string{"", "$HOME"}, want: `'my prog' '' '$HOME'`},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.want), func(t *testing.T) {
+			got := shlex.FormatCommand(tt.name, tt.args...)
+			if got != tt.want {
+				t.Errorf("FormatCommand = %s, want %s", got, tt.want)
+			}
+			argv := append([]string{tt.name}, tt.args...)
+			if split := shlex.Split(got); !reflect.DeepEqual(split, argv) {
+				t.Errorf("Split(FormatCommand) = %#v, want %#v", split, argv)
+			}
+		})
+	}
+}
+
+func TestFormatCmd(t *testing.T) {
+	for i, tt := range []struct {
+		cmd  *exec.Cmd
+		want string
+		argv []string
+	}{
+		{
+			cmd:  &exec.Cmd{Path: "/bin/echo", Args: []string{"echo", "it's", "a\nb"}},
+			want: "/bin/echo \"it's\" 'a\nb'",
+			argv: []string{"/bin/echo", "it's", "a\nb"},
+		},
+		{
+			cmd:  &exec.Cmd{Path: "/bin/true"},
+			want: "/bin/true",
+			argv: []string{"/bin/true"},
+		},
+		{
+			cmd:  &exec.Cmd{Path: "/bin/env", Args: []string{"env"}, Env: []string{"A=1 2", "B=", "junk", "C-D=x"}},
+			want: "env -i A='1 2' B='' C-D=x /bin/env",
+			argv: []string{"env", "-i", "A=1 2", "B=", "C-D=x", "/bin/env"},
+		},
+		{
+			cmd:  &exec.Cmd{Path: "/bin/sh", Args: []string{"sh"}, Env: []string{}},
+			want: "env -i /bin/sh",
+			argv: []string{"env", "-i", "/bin/sh"},
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.want), func(t *testing.T) {
+			got := shlex.FormatCmd(tt.cmd)
+			if got != tt.want {
+				t.Errorf("FormatCmd = %s, want %s", got, tt.want)
+			}
+			if split := shlex.Split(got); !reflect.DeepEqual(split, tt.argv) {
+				t.Errorf("Split(FormatCmd) = %#v, want %#v", split, tt.argv)
+			}
+		})
+	}
+}
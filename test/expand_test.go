@@ -0,0 +1,276 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestValidateTemplate(t *testing.T) {
+	allowed := []string{"HOME", "USER", "1", "A"}
+
+	for i, tt := range []struct {
+		desc      string
+		in        string
+		want      error
+		wantExp   string
+		wantIndex int
+	}{
+		{
+			desc: "no expansions",
+			in:   "ls -la /tmp",
+		},
+		{
+			desc: "allowed vars",
+			in:   `cp $1 "${HOME}/$USER.bak"`,
+		},
+		{
+			desc:      "disallowed var",
+			in:        "echo $PATH",
+			want:      shlex.ErrVariableNotAllowed,
+			wantExp:   "$PATH",
+			wantIndex: 5,
+		},
+		{
+			desc:      "disallowed var in double quotes",
+			in:        `echo "$HOME $SECRET"`,
+			want:      shlex.ErrVariableNotAllowed,
+			wantExp:   "$SECRET",
+			wantIndex: 12,
+		},
+		{
+			desc: "single quoted",
+			in:   `echo '$SECRET $(id)'`,
+		},
+		{
+			desc: "escaped",
+			in:   `echo \$SECRET "\$(id)" \`,
+		},
+		{
+			desc: "comment",
+			in:   "echo hi # $(id)",
+		},
+		{
+			desc: "lone dollar",
+			in:   "echo $ 5$",
+		},
+		{
+			desc:      "special param",
+			in:        "echo $1 $@",
+			want:      shlex.ErrVariableNotAllowed,
+			wantExp:   "$@",
+			wantIndex: 8,
+		},
+		{
+			desc:      "nested default",
+			in:        "echo ${HOME:-$SECRET}",
+			want:      shlex.ErrVariableNotAllowed,
+			wantExp:   "$SECRET",
+			wantIndex: 13,
+		},
+		{
+			desc:      "length",
+			in:        "echo ${#SECRET}",
+			want:      shlex.ErrVariableNotAllowed,
+			wantExp:   "${#SECRET}",
+			wantIndex: 5,
+		},
+		{
+			desc:      "command substitution",
+			in:        `echo "$(cat /etc/passwd)"`,
+			want:      shlex.ErrCommandSubstitution,
+			wantExp:   "$(cat /etc/passwd)",
+			wantIndex: 6,
+		},
+		{
+			desc:      "backtick",
+			in:        "echo `id`",
+			want:      shlex.ErrCommandSubstitution,
+			wantExp:   "`id`",
+			wantIndex: 5,
+		},
+		{
+			desc:      "process substitution",
+			in:        "diff <(ls a) b",
+			want:      shlex.ErrCommandSubstitution,
+			wantExp:   "<(ls a)",
+			wantIndex: 5,
+		},
+		{
+			desc:      "arithmetic",
+			in:        "echo $((1 + 2))",
+			want:      shlex.ErrArithmeticExpansion,
+			wantExp:   "$((1 + 2))",
+			wantIndex: 5,
+		},
+		{
+			desc:      "unterminated",
+			in:        "echo ${HOME",
+			want:      shlex.ErrUnterminatedExpansion,
+			wantExp:   "${HOME",
+			wantIndex: 5,
+		},
+		{
+			desc:      "escaped quote in ansi-c quotes",
+			in:        `echo $'\'' $(id) ''`,
+			want:      shlex.ErrCommandSubstitution,
+			wantExp:   "$(id)",
+			wantIndex: 11,
+		},
+		{
+			desc:      "escaped blank before hash",
+			in:        `echo a\ #$(id)`,
+			want:      shlex.ErrCommandSubstitution,
+			wantExp:   "$(id)",
+			wantIndex: 9,
+		},
+		{
+			desc:      "hash after unicode blank",
+			in:        "echo a\u00a0# $(id)",
+			want:      shlex.ErrCommandSubstitution,
+			wantExp:   "$(id)",
+			wantIndex: 10,
+		},
+		{
+			desc:      "single quotes in double-quoted default",
+			in:        `echo "${A:-'$(echo PWNED)'}"`,
+			want:      shlex.ErrCommandSubstitution,
+			wantExp:   "$(echo PWNED)",
+			wantIndex: 12,
+		},
+		{
+			desc:      "ansi-c quotes in double-quoted default",
+			in:        `echo "${A:-$'$(echo PWNED)'}"`,
+			want:      shlex.ErrCommandSubstitution,
+			wantExp:   "$(echo PWNED)",
+			wantIndex: 13,
+		},
+		{
+			desc:      "single-quoted variable in double-quoted default",
+			in:        `echo "${A:-'${SECRET}'}"`,
+			want:      shlex.ErrVariableNotAllowed,
+			wantExp:   "${SECRET}",
+			wantIndex: 12,
+		},
+		{
+			desc: "single quotes in unquoted default",
+			in:   `echo ${A:-'$(echo PWNED)'}`,
+		},
+		{
+			desc:      "unterminated quote in substitution",
+			in:        "echo $(echo ')",
+			want:      shlex.ErrUnterminatedExpansion,
+			wantExp:   "$(echo ')",
+			wantIndex: 5,
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			err := shlex.ValidateTemplate(tt.in, allowed)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("ValidateTemplate = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil {
+				return
+			}
+			var te *shlex.TemplateError
+			if !errors.As(err, &te) {
+				t.Fatalf("ValidateTemplate = %T, want *TemplateError", err)
+			}
+			if te.Expansion != tt.wantExp || te.Offset != tt.wantIndex {
+				t.Errorf("ValidateTemplate = %q at %d, want %q at %d", te.Expansion, te.Offset, tt.wantExp, tt.wantIndex)
+			}
+		})
+	}
+}
+
+func TestValidateTemplateSyntax(t *testing.T) {
+	allowed := []string{"HOME", "user"}
+
+	for i, tt := range []struct {
+		desc      string
+		in        string
+		syn       shlex.VarSyntax
+		want      error
+		wantExp   string
+		wantIndex int
+	}{
+		{
+			desc: "percent allowed",
+			in:   `copy %HOME%\a "%HOME%"`,
+			syn:  shlex.PercentSyntax,
+		},
+		{
+			desc:      "percent disallowed",
+			in:        "echo %HOME% %PATH%",
+			syn:       shlex.PercentSyntax,
+			want:      shlex.ErrVariableNotAllowed,
+			wantExp:   "%PATH%",
+			wantIndex: 12,
+		},
+		{
+			desc: "template with blanks",
+			in:   "ssh {{ user }}@host $SECRET $(id)",
+			syn:  shlex.TemplateSyntax,
+		},
+		{
+			desc:      "template disallowed in double quotes",
+			in:        `echo "{{token}}"`,
+			syn:       shlex.TemplateSyntax,
+			want:      shlex.ErrVariableNotAllowed,
+			wantExp:   "{{token}}",
+			wantIndex: 6,
+		},
+		{
+			desc: "template single quoted or escaped",
+			in:   `echo '{{token}}' \{{token}} # {{token}}`,
+			syn:  shlex.TemplateSyntax,
+		},
+		{
+			desc:      "template after escaped blank and ansi-c quotes",
+			in:        `echo $'\'' a\ #{{token}}`,
+			syn:       shlex.TemplateSyntax,
+			want:      shlex.ErrVariableNotAllowed,
+			wantExp:   "{{token}}",
+			wantIndex: 15,
+		},
+		{
+			desc:      "brace",
+			in:        "cd ${HOME} && echo ${PWD}",
+			syn:       shlex.BraceSyntax,
+			want:      shlex.ErrVariableNotAllowed,
+			wantExp:   "${PWD}",
+			wantIndex: 19,
+		},
+		{
+			desc:      "unterminated",
+			in:        "echo {{user",
+			syn:       shlex.TemplateSyntax,
+			want:      shlex.ErrUnterminatedExpansion,
+			wantExp:   "{{user",
+			wantIndex: 5,
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			err := shlex.ValidateTemplateSyntax(tt.in, allowed, tt.syn)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("ValidateTemplateSyntax = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil {
+				return
+			}
+			var te *shlex.TemplateError
+			if !errors.As(err, &te) {
+				t.Fatalf("ValidateTemplateSyntax = %T, want *TemplateError", err)
+			}
+			if te.Expansion != tt.wantExp || te.Offset != tt.wantIndex {
+				t.Errorf("ValidateTemplateSyntax = %q at %d, want %q at %d", te.Expansion, te.Offset, tt.wantExp, tt.wantIndex)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestExpander(t *testing.T) {
+	vars := map[string]string{
+		"HOME":  "/home/me",
+		"WORDS": " two  words ",
+		"EMPTY": "",
+		"1":     "first",
+		"GLOB":  "*.go",
+	}
+	files := []string{"a.go", "b.go", "c.txt"}
+	errFailed := errors.New("failed")
+
+	full := &shlex.Expander{
+		Var: func(name string) (string, bool) {
+			v, ok := vars[name]
+			return v, ok
+		},
+		Subst: func(cmd string) (string, error) {
+			if cmd == "fail" {
+				return "", errFailed
+			}
+			return "out of " + cmd + "\n\n", nil
+		},
+		Glob: func(pattern string) ([]string, error) {
+			var m []string
+			for _, f := range files {
+				if ok, _ := path.Match(pattern, f); ok {
+					m = append(m, f)
+				}
+			}
+			return m, nil
+		},
+	}
+
+	for i, tt := range []struct {
+		desc    string
+		ex      *shlex.Expander
+		in      string
+		want    []string
+		wantErr error
+	}{
+		{
+			desc: "no expansions",
+			ex:   full,
+			in:   `echo 'a b' "c d"`,
+			want: []string{"echo", "a b", "c d"},
+		},
+		{
+			desc: "variables",
+			ex:   full,
+			in:   `$HOME "${HOME}/x" \$HOME '$HOME' $1`,
+			want: []string{"/home/me", "/home/me/x", "$HOME", "$HOME", "first"},
+		},
+		{
+			desc: "field splitting",
+			ex:   full,
+			in:   `a$WORDS "$WORDS" $EMPTY "$EMPTY" $UNSET`,
+			want: []string{"a", "two", "words", " two  words ", ""},
+		},
+		{
+			desc: "defaults",
+			ex:   full,
+			in:   `${UNSET-def} ${EMPTY-def} ${EMPTY:-"d e"} ${HOME:+alt} ${UNSET+alt} ${#HOME}`,
+			want: []string{"def", "d e", "alt", "8"},
+		},
+		{
+			desc: "nested default",
+			ex:   full,
+			in:   `${UNSET:-$HOME}`,
+			want: []string{"/home/me"},
+		},
+		{
+			desc: "command substitution",
+			ex:   full,
+			in:   "\"$(ls -l)\" `date`",
+			want: []string{"out of ls -l", "out", "of", "date"},
+		},
+		{
+			desc: "glob",
+			ex:   full,
+			in:   `*.go '*.go' *.none $GLOB "$GLOB"`,
+			want: []string{"a.go", "b.go", "*.go", "*.none", "a.go", "b.go", "*.go"},
+		},
+		{
+			desc: "dollar quotes",
+			ex:   full,
+			in:   `$"x" $"$HOME" a$''b $'a\tb' $'*.go' "$"x`,
+			want: []string{"x", "/home/me", "ab", "a\tb", "*.go", "$x"},
+		},
+		{
+			desc: "nil callbacks",
+			ex:   &shlex.Expander{},
+			in:   `"$HOME" $(id) *.go`,
+			want: []string{"$HOME", "$(id)", "*.go"},
+		},
+		{
+			desc: "arithmetic left alone",
+			ex:   full,
+			in:   `$((1+2))`,
+			want: []string{"$((1+2))"},
+		},
+		{
+			desc:    "unsupported",
+			ex:      full,
+			in:      `${HOME%/me}`,
+			wantErr: shlex.ErrUnsupportedExpansion,
+		},
+		{
+			desc:    "unterminated",
+			ex:      full,
+			in:      `${HOME`,
+			wantErr: shlex.ErrUnterminatedExpansion,
+		},
+		{
+			desc:    "substitution error",
+			ex:      full,
+			in:      `ok $(fail)`,
+			wantErr: errFailed,
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			tokens, err := shlex.Lex(tt.in)
+			if err != nil {
+				t.Fatalf("Lex = %v", err)
+			}
+			got, err := tt.ex.Expand(tokens)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Expand = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expand = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expand = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpanderAlias(t *testing.T) {
+	aliases := map[string]string{
+		"ls":    "ls -F",
+		"ll":    "ls -l",
+		"sudo":  "sudo ",
+		"g":     "git; g",
+		"loop1": "loop2",
+		"loop2": "loop1",
+		"bad":   "echo 'oops",
+	}
+	// chain0 is an alias for chain1, and so on.
+	for i := 0; i < 20; i++ {
+		aliases[fmt.Sprintf("chain%d", i)] = fmt.Sprintf("chain%d", i+1)
+	}
+	ex := &shlex.Expander{
+		Alias: func(name string) (string, bool) {
+			v, ok := aliases[name]
+			return v, ok
+		},
+	}
+
+	for i, tt := range []struct {
+		desc     string
+		in       string
+		maxDepth int
+		want     []string
+		wantErr  error
+	}{
+		{
+			desc: "self reference",
+			in:   "ls /tmp",
+			want: []string{"ls", "-F", "/tmp"},
+		},
+		{
+			desc: "nested",
+			in:   "ll ll",
+			want: []string{"ls", "-F", "-l", "ll"},
+		},
+		{
+			desc: "trailing blank",
+			in:   "sudo ll",
+			want: []string{"sudo", "ls", "-F", "-l"},
+		},
+		{
+			desc: "quoted",
+			in:   `\ll 'll'`,
+			want: []string{"ll", "ll"},
+		},
+		{
+			desc: "command position",
+			in:   "echo ll; ll|ll",
+			want: []string{"echo", "ll", ";", "ls", "-F", "-l", "|", "ls", "-F", "-l"},
+		},
+		{
+			desc: "not again in own value",
+			in:   "g",
+			want: []string{"git", ";", "g"},
+		},
+		{
+			desc: "mutual recursion",
+			in:   "loop1",
+			want: []string{"loop1"},
+		},
+		{
+			desc:    "default depth",
+			in:      "chain0",
+			wantErr: &shlex.RecursionError{Name: "chain16", Max: shlex.DefaultMaxDepth},
+		},
+		{
+			desc:     "max depth",
+			in:       "chain0",
+			maxDepth: 3,
+			wantErr:  &shlex.RecursionError{Name: "chain3", Max: 3},
+		},
+		{
+			desc:     "within max depth",
+			in:       "chain17",
+			maxDepth: 3,
+			want:     []string{"chain20"},
+		},
+		{
+			desc:    "bad alias",
+			in:      "bad",
+			wantErr: shlex.ErrUnterminatedSingleQuote,
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			tokens, err := shlex.Lex(tt.in, shlex.WithOperators(true))
+			if err != nil {
+				t.Fatalf("Lex = %v", err)
+			}
+			ex.MaxDepth = tt.maxDepth
+			got, err := ex.Expand(tokens)
+			if want, ok := tt.wantErr.(*shlex.RecursionError); ok {
+				var re *shlex.RecursionError
+				if !errors.As(err, &re) || *re != *want {
+					t.Fatalf("Expand = %v, want %v", err, want)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expand = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) && tt.wantErr == nil {
+				t.Errorf("Expand = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestExplode(t *testing.T) {
+	for i, tt := range []struct {
+		desc    string
+		in      string
+		want    []string
+		wantErr error
+	}{
+		{
+			desc: "single command",
+			in:   "  echo   'a  b'  ",
+			want: []string{"echo 'a  b'"},
+		},
+		{
+			desc: "cron entry",
+			in:   `cd /srv/app && git pull -q || exit 1; ./build.sh 2>&1 | tee -a "$LOG" > /dev/null &`,
+			want: []string{
+				"cd /srv/app &&",
+				"  git pull -q ||",
+				"  exit 1",
+				"./build.sh 2>&1 |",
+				`  tee -a "$LOG" > /dev/null &`,
+			},
+		},
+		{
+			desc: "substitutions",
+			in:   `x=$(a; b | c);y="&&;";echo ${z:-d;e}`,
+			want: []string{"x=$(a; b | c)", `y="&&;"`, "echo ${z:-d;e}"},
+		},
+		{
+			desc: "compound",
+			in:   "if a; then b; else c; fi; case $x in y) z;; esac",
+			want: []string{"if a", "then b", "else c", "fi", "case $x in y) z ;;", "esac"},
+		},
+		{
+			desc: "comment",
+			in:   "a; b; # run a and b",
+			want: []string{"a", "b # run a and b"},
+		},
+		{
+			desc: "comment after command",
+			in:   "a && b   #  c",
+			want: []string{"a &&", "  b #  c"},
+		},
+		{
+			desc: "lines",
+			in:   "a; b\nc && # d\n e",
+			want: []string{"a", "b", "c && # d", "  e"},
+		},
+		{
+			desc: "empty",
+			in:   " ; ; ",
+			want: nil,
+		},
+		{
+			desc:    "unterminated",
+			in:      "a; 'b",
+			wantErr: shlex.ErrUnterminatedSingleQuote,
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			got, err := shlex.Explode(tt.in)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Explode = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Explode = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
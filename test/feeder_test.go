@@ -0,0 +1,132 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+// feedAll feeds in to f in chunks of size n and closes it.
+func feedAll(f *shlex.Feeder, in string, n int) ([]string, error) {
+	got := []string{}
+	for len(in) > 0 {
+		chunk := in
+		if len(chunk) > n {
+			chunk = chunk[:n]
+		}
+		in = in[len(chunk):]
+		words, err := f.Feed([]byte(chunk))
+		got = append(got, words...)
+		if err != nil {
+			return got, err
+		}
+	}
+	words, err := f.Close()
+	return append(got, words...), err
+}
+
+func TestFeeder(t *testing.T) {
+	for i, tt := range []struct {
+		in   string
+		opts []shlex.Option
+	}{
+		{in: ""},
+		{in: "echo hello world"},
+		{in: `one "two three" 'four five' six\ seven "" ''`},
+		{in: "a # comment 'x\nb \"c\nd\" e\\\nf"},
+		{in: "héllo 😀 wörld x"},
+		{in: "a|b&&c>>d;e 2>&1", opts: []shlex.Option{shlex.WithOperators(true)}},
+		{in: "a\r\nb\r\n", opts: []shlex.Option{shlex.WithStripCR(true)}},
+		{in: "don't stop", opts: []shlex.Option{shlex.WithApostrophes(true)}},
+		{in: `$'a\x41\u00e9\101\cA$' x "$"b`, opts: []shlex.Option{shlex.Bash}},
+		{in: "a \\\nb c\\\nd", opts: []shlex.Option{shlex.POSIX}},
+		{in: "a\xffb \xe2\x82 c"},
+	} {
+		want := shlex.Split(tt.in, tt.opts...)
+		for n := 1; n <= len(tt.in)+1; n++ {
+			t.Run(fmt.Sprintf("Test [%02d] %s/%d", i, tt.in, n), func(t *testing.T) {
+				got, err := feedAll(shlex.NewFeeder(tt.opts...), tt.in, n)
+				if err != nil {
+					t.Fatalf("Feed = %v", err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("Feed = %#v, want %#v", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestFeederIncremental(t *testing.T) {
+	f := shlex.NewFeeder()
+	for i, tt := range []struct {
+		chunk string
+		want  []string
+	}{
+		{chunk: "ls -", want: []string{"ls"}},
+		{chunk: "l 'my ", want: []string{"-l"}},
+		{chunk: "dir' ", want: []string{"my dir"}},
+		{chunk: "a\\", want: nil},
+		{chunk: " b", want: nil},
+		{chunk: " c", want: []string{"a b"}},
+	} {
+		got, err := f.Feed([]byte(tt.chunk))
+		if err != nil {
+			t.Fatalf("Feed %d = %v", i, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Feed %d (%q) = %#v, want %#v", i, tt.chunk, got, tt.want)
+		}
+	}
+	got, err := f.Close()
+	if want := []string{"c"}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Close = %#v, %v, want %#v, nil", got, err, want)
+	}
+	if _, err := f.Feed([]byte("x")); err != shlex.ErrFeederClosed {
+		t.Errorf("Feed after Close = %v, want %v", err, shlex.ErrFeederClosed)
+	}
+}
+
+func TestFeederLongWord(t *testing.T) {
+	// Each chunk is scanned once, so a long word fed a byte at a time
+	// takes time linear in its length.
+	in := "'" + strings.Repeat("quoted ", 1<<17) + "' x"
+	got, err := feedAll(shlex.NewFeeder(), in, 1)
+	if want := shlex.Split(in); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Feed = %d words, %v, want %d", len(got), err, len(want))
+	}
+}
+
+func TestFeederErrors(t *testing.T) {
+	f := shlex.NewFeeder(shlex.WithSource("net"))
+	if _, err := f.Feed([]byte("ok\n\"not")); err != nil {
+		t.Fatalf("Feed = %v", err)
+	}
+	if _, err := f.Feed([]byte(" ok")); err != nil {
+		t.Fatalf("Feed = %v", err)
+	}
+	got, err := f.Close()
+	var pe *shlex.ParseError
+	if !errors.As(err, &pe) || !errors.Is(err, shlex.ErrUnterminatedDoubleQuote) {
+		t.Fatalf("Close = %v, want *ParseError wrapping %v", err, shlex.ErrUnterminatedDoubleQuote)
+	}
+	if want := "net:2:1: unterminated double quote"; pe.Error() != want {
+		t.Errorf("Close = %q, want %q", pe.Error(), want)
+	}
+	if want := []string{"not ok"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Close = %#v, want %#v", got, want)
+	}
+
+	got, err = feedAll(shlex.NewFeeder(shlex.WithStrictErrors(false)), "a 'b", 1)
+	if want := []string{"a", "b"}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("lenient Feed = %#v, %v, want %#v, nil", got, err, want)
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestFileList(t *testing.T) {
+	names := []string{"plain.txt", "with space", "new\nline", "it's", "#hash", "", "-dash"}
+
+	enc := shlex.EncodeFileList(names)
+	want := "plain.txt\n'with space'\n'new\nline'\n\"it's\"\n'#hash'\n''\n-dash\n"
+	if enc != want {
+		t.Errorf("EncodeFileList = %q, want %q", enc, want)
+	}
+	if got := shlex.DecodeFileList(enc); !reflect.DeepEqual(got, names) {
+		t.Errorf("DecodeFileList = %#v, want %#v", got, names)
+	}
+
+	if got := shlex.DecodeFileList(`a "b c" 'd'`); !reflect.DeepEqual(got, []string{"a", "b c", "d"}) {
+		t.Errorf("DecodeFileList = %#v", got)
+	}
+	if got := shlex.DecodeFileList(shlex.EncodeFileList(nil)); len(got) != 0 {
+		t.Errorf("DecodeFileList(empty) = %#v, want none", got)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestFindArg(t *testing.T) {
+	argv := []string{"cc", "-O2", "-o", "out file", "-ofile", "main.c"}
+	for i, tt := range []struct {
+		pattern string
+		want    int
+	}{
+		{pattern: "-o", want: 2},
+		{pattern: "-o*", want: 2},
+		{pattern: "-o?*", want: 4},
+		{pattern: "out file", want: 3},
+		{pattern: "*.c", want: 5},
+		{pattern: "-x", want: -1},
+		{pattern: "[", want: -1},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.pattern), func(t *testing.T) {
+			if got := shlex.FindArg(argv, tt.pattern); got != tt.want {
+				t.Errorf("FindArg(%q) = %d, want %d", tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindArgSpan(t *testing.T) {
+	line := `cc  -O2 "-o" 'out file' main.c # -x`
+	for i, tt := range []struct {
+		pattern   string
+		wantIndex int
+		wantRaw   string
+	}{
+		{pattern: "-o", wantIndex: 2, wantRaw: `"-o"`},
+		{pattern: "out*", wantIndex: 3, wantRaw: `'out file'`},
+		{pattern: "-O2", wantIndex: 1, wantRaw: "-O2"},
+		{pattern: "-x", wantIndex: -1},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.pattern), func(t *testing.T) {
+			index, start, end := shlex.FindArgSpan(line, tt.pattern)
+			if index != tt.wantIndex {
+				t.Fatalf("FindArgSpan(%q) = %d, want %d", tt.pattern, index, tt.wantIndex)
+			}
+			if index < 0 {
+				if start != -1 || end != -1 {
+					t.Errorf("FindArgSpan(%q) span = [%d, %d), want [-1, -1)", tt.pattern, start, end)
+				}
+				return
+			}
+			if raw := line[start:end]; raw != tt.wantRaw {
+				t.Errorf("FindArgSpan(%q) span = %q, want %q", tt.pattern, raw, tt.wantRaw)
+			}
+		})
+	}
+}
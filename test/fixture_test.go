@@ -0,0 +1,90 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+	"github.com/hugelgupf/go-shlex/shlextest"
+)
+
+var dialects = map[string][]shlex.Option{
+	"default":   nil,
+	"operators": {shlex.WithOperators(true)},
+}
+
+func TestFixtures(t *testing.T) {
+	fixtures, err := shlextest.LoadFixtures("testdata/*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatal("no fixtures in testdata")
+	}
+	shlextest.RunFixtures(t, fixtures, dialects)
+}
+
+func TestParseFixtures(t *testing.T) {
+	for i, tt := range []struct {
+		desc    string
+		in      string
+		want    []shlextest.Fixture
+		wantErr string
+	}{
+		{
+			desc: "cases",
+			in:   "# c\nin: \"a b\"\nx: \"a\" `b`\n\nin: \"\"\nx:\n",
+			want: []shlextest.Fixture{
+				{Name: "f:2", Input: "a b", Want: map[string][]string{"x": {"a", "b"}}},
+				{Name: "f:5", Input: "", Want: map[string][]string{"x": {}}},
+			},
+		},
+		{
+			desc:    "missing colon",
+			in:      "in \"a\"\n",
+			wantErr: "f:1: missing colon",
+		},
+		{
+			desc:    "dialect before input",
+			in:      "x: \"a\"\n",
+			wantErr: `f:1: dialect "x" before input`,
+		},
+		{
+			desc:    "bad literal",
+			in:      "in: \"a\n",
+			wantErr: `f:1: bad string literal at "\"a"`,
+		},
+		{
+			desc:    "duplicate dialect",
+			in:      "in: \"a\"\nx: \"a\"\nx: \"a\"\n",
+			wantErr: `f:3: duplicate dialect "x"`,
+		},
+		{
+			desc:    "two inputs",
+			in:      "in: \"a\"\nin: \"b\"\n",
+			wantErr: "f:2: want a single input starting a case",
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			got, err := shlextest.ParseFixtures(strings.NewReader(tt.in), "f")
+			if len(tt.wantErr) > 0 {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ParseFixtures = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseFixtures = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseFixtures = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
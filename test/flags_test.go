@@ -0,0 +1,122 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestMergeFlags(t *testing.T) {
+	spec := map[string]shlex.FlagKind{
+		"-v":        shlex.FlagCount,
+		"-x":        shlex.FlagCount,
+		"--verbose": shlex.FlagCount,
+		"-I":        shlex.FlagList,
+		"--include": shlex.FlagList,
+		"-o":        shlex.FlagLast,
+	}
+
+	for i, tt := range []struct {
+		desc       string
+		args       []string
+		wantCounts map[string]int
+		wantValues map[string][]string
+		wantArgs   []string
+		wantErr    error
+	}{
+		{
+			desc:       "counts",
+			args:       []string{"-v", "a", "-v", "--verbose", "-vvx"},
+			wantCounts: map[string]int{"-v": 4, "-x": 1, "--verbose": 1},
+			wantValues: map[string][]string{},
+			wantArgs:   []string{"a"},
+		},
+		{
+			desc:       "lists",
+			args:       []string{"--include", "a", "--include=b", "-I", "c", "-Id", "-vIe"},
+			wantCounts: map[string]int{"-v": 1},
+			wantValues: map[string][]string{"--include": {"a", "b"}, "-I": {"c", "d", "e"}},
+		},
+		{
+			desc:       "last",
+			args:       []string{"-o", "a", "x", "-ob"},
+			wantCounts: map[string]int{},
+			wantValues: map[string][]string{"-o": {"b"}},
+			wantArgs:   []string{"x"},
+		},
+		{
+			desc:       "unknown and double dash",
+			args:       []string{"-q", "-vq", "--color=auto", "--", "-v", "--"},
+			wantCounts: map[string]int{},
+			wantValues: map[string][]string{},
+			wantArgs:   []string{"-q", "-vq", "--color=auto", "-v", "--"},
+		},
+		{
+			desc:    "missing value",
+			args:    []string{"-v", "--include"},
+			wantErr: shlex.ErrMissingFlagValue,
+		},
+		{
+			desc:    "missing value in cluster",
+			args:    []string{"-vI"},
+			wantErr: shlex.ErrMissingFlagValue,
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			got, err := shlex.MergeFlags(tt.args, spec)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("MergeFlags = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got.Counts, tt.wantCounts) {
+				t.Errorf("Counts = %v, want %v", got.Counts, tt.wantCounts)
+			}
+			if !reflect.DeepEqual(got.Values, tt.wantValues) {
+				t.Errorf("Values = %v, want %v", got.Values, tt.wantValues)
+			}
+			if !reflect.DeepEqual(got.Args, tt.wantArgs) {
+				t.Errorf("Args = %#v, want %#v", got.Args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestExtractFlagValue(t *testing.T) {
+	for i, tt := range []struct {
+		line  string
+		flag  string
+		want  string
+		found bool
+	}{
+		{line: `prog --config "/etc/my app.conf" -v`, flag: "--config", want: "/etc/my app.conf", found: true},
+		{line: `prog --config='/etc/a b' -v`, flag: "--config", want: "/etc/a b", found: true},
+		{line: `prog -o out.bin`, flag: "-o", want: "out.bin", found: true},
+		{line: `prog -o'out file'`, flag: "-o", want: "out file", found: true},
+		{line: `prog -o=x`, flag: "-o", want: "=x", found: true},
+		{line: `find . -name '*.go'`, flag: "-name", want: "*.go", found: true},
+		{line: `find . -name=x -namex`, flag: "-name", want: "x", found: true},
+		{line: `prog --level=1 --level 2`, flag: "--level", want: "2", found: true},
+		{line: `prog --level= x`, flag: "--level", want: "", found: true},
+		{line: `prog --levels=1`, flag: "--level"},
+		{line: `prog --level`, flag: "--level"},
+		{line: `prog -- --level 1`, flag: "--level"},
+		{line: `prog --level 1 -- --level 2`, flag: "--level", want: "1", found: true},
+		{line: `prog "--level" 3`, flag: "--level", want: "3", found: true},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.line), func(t *testing.T) {
+			got, found := shlex.ExtractFlagValue(tt.line, tt.flag)
+			if got != tt.want || found != tt.found {
+				t.Errorf("ExtractFlagValue(%q) = %q, %v, want %q, %v", tt.flag, got, found, tt.want, tt.found)
+			}
+		})
+	}
+}
@@ -1,6 +1,6 @@
 module github.com/hugelgupf/go-shlex/test
 
-go 1.14
+go 1.18
 
 require (
 	github.com/anmitsu/go-shlex v0.0.0-20200514113438-38f4b401e2be
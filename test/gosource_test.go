@@ -0,0 +1,76 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestGoExecCommand(t *testing.T) {
+	for i, tt := range []struct {
+		in      string
+		want    string
+		wantErr error
+	}{
+		{
+			in:   `tar -xzf 'my file.tgz'`,
+			want: `exec.Command("tar", "-xzf", "my file.tgz")`,
+		},
+		{
+			in:   `printf '%s\n' "say \"hi\"" ''`,
+			want: "exec.Command(\"printf\", `%s\\n`, `say \"hi\"`, \"\")",
+		},
+		{
+			in:   "echo 'tab\there' 'new\nline' 'back`quote\\'",
+			want: `exec.Command("echo", "tab\there", "new\nline", "back` + "`" + `quote\\")`,
+		},
+		{
+			in:   `  ls *.go | wc -l  `,
+			want: `exec.Command("sh", "-c", "ls *.go | wc -l")`,
+		},
+		{
+			in:   `cd /tmp`,
+			want: `exec.Command("sh", "-c", "cd /tmp")`,
+		},
+		{
+			in:   "echo a\\ #`id`",
+			want: `exec.Command("sh", "-c", "echo a\\ #` + "`id`" + `")`,
+		},
+		{
+			in:   `echo $'a\nb'`,
+			want: "exec.Command(\"sh\", \"-c\", `echo $'a\\nb'`)",
+		},
+		{
+			in:   "ls\nrm x\n",
+			want: `exec.Command("sh", "-c", "ls\nrm x")`,
+		},
+		{
+			in:   "echo a\rb",
+			want: `exec.Command("sh", "-c", "echo a\rb")`,
+		},
+		{
+			in:      "  # nothing",
+			wantErr: shlex.ErrEmptyCommand,
+		},
+		{
+			in:      `echo "unterminated`,
+			wantErr: shlex.ErrUnterminatedDoubleQuote,
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.in), func(t *testing.T) {
+			got, err := shlex.GoExecCommand(tt.in)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GoExecCommand = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GoExecCommand = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
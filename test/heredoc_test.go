@@ -0,0 +1,92 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestHeredoc(t *testing.T) {
+	for i, tt := range []struct {
+		desc    string
+		argv    []string
+		payload string
+		want    string
+		wantErr error
+	}{
+		{
+			desc:    "simple",
+			argv:    []string{"cat"},
+			payload: "hello $USER\n`id`\n",
+			want:    "cat <<'EOF'\nhello $USER\n`id`\nEOF\n",
+		},
+		{
+			desc:    "missing newline",
+			argv:    []string{"tee", "my file"},
+			payload: "a\nb",
+			want:    "tee 'my file' <<'EOF'\na\nb\nEOF\n",
+		},
+		{
+			desc:    "collision",
+			argv:    []string{"sh"},
+			payload: "cat <<EOF\nx\nEOF\nEOF_1\n",
+			want:    "sh <<'EOF_2'\ncat <<EOF\nx\nEOF\nEOF_1\nEOF_2\n",
+		},
+		{
+			desc:    "delimiter not on its own line",
+			argv:    []string{"cat"},
+			payload: " EOF\nEOF \n",
+			want:    "cat <<'EOF'\n EOF\nEOF \nEOF\n",
+		},
+		{
+			desc: "empty",
+			argv: []string{"cat"},
+			want: "cat <<'EOF'\nEOF\n",
+		},
+		{
+			desc:    "NUL",
+			argv:    []string{"cat"},
+			payload: "a\x00b\n",
+			wantErr: shlex.ErrNUL,
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			got, err := shlex.Heredoc(tt.argv, tt.payload)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Heredoc = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Heredoc = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeredocDelimiter(t *testing.T) {
+	for i, tt := range []struct {
+		body      string
+		wantDelim string
+		wantQuote bool
+	}{
+		{body: "", wantDelim: "EOF"},
+		{body: "plain text\n", wantDelim: "EOF"},
+		{body: "EOF\nEOF_1\n", wantDelim: "EOF_2"},
+		{body: "not EOF\n EOF\n", wantDelim: "EOF"},
+		{body: "hello $USER\n", wantDelim: "EOF", wantQuote: true},
+		{body: "`id`\n", wantDelim: "EOF", wantQuote: true},
+		{body: "C:\\dir\nEOF", wantDelim: "EOF_1", wantQuote: true},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %q", i, tt.body), func(t *testing.T) {
+			delim, quote := shlex.HeredocDelimiter(tt.body)
+			if delim != tt.wantDelim || quote != tt.wantQuote {
+				t.Errorf("HeredocDelimiter = (%q, %v), want (%q, %v)", delim, quote, tt.wantDelim, tt.wantQuote)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestParseBashHistory(t *testing.T) {
+	for _, tt := range []struct {
+		desc string
+		in   string
+		want []shlex.HistoryEntry
+	}{
+		{
+			desc: "plain",
+			in:   "ls -la\n\necho 'a b'\n",
+			want: []shlex.HistoryEntry{
+				{Line: "ls -la", Args: []string{"ls", "-la"}},
+				{Line: "echo 'a b'", Args: []string{"echo", "a b"}},
+			},
+		},
+		{
+			desc: "timestamps",
+			in:   "#1600000000\ngit commit -m 'fix it'\n#1600000060\nfor f in *; do\necho $f\ndone\n",
+			want: []shlex.HistoryEntry{
+				{
+					Time: time.Unix(1600000000, 0),
+					Line: "git commit -m 'fix it'",
+					Args: []string{"git", "commit", "-m", "fix it"},
+				},
+				{
+					Time: time.Unix(1600000060, 0),
+					Line: "for f in *; do\necho $f\ndone",
+					Args: []string{"for", "f", "in", "*;", "do", "echo", "$f", "done"},
+				},
+			},
+		},
+		{
+			desc: "comment is not a timestamp",
+			in:   "#not a time\n",
+			want: []shlex.HistoryEntry{
+				{Line: "#not a time", Args: []string{}},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := shlex.ParseBashHistory(strings.NewReader(tt.in))
+			if err != nil {
+				t.Fatalf("ParseBashHistory = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseBashHistory = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseZshHistory(t *testing.T) {
+	in := ": 1600000000:5;make 'all targets'\n" +
+		": 1600000010:0;echo one\\\ntwo\n" +
+		"plain command\n" +
+		": 1600000020:0;echo caf\xc3\x83\x89\n"
+	want := []shlex.HistoryEntry{
+		{
+			Time:     time.Unix(1600000000, 0),
+			Duration: 5 * time.Second,
+			Line:     "make 'all targets'",
+			Args:     []string{"make", "all targets"},
+		},
+		{
+			Time: time.Unix(1600000010, 0),
+			Line: "echo one\ntwo",
+			Args: []string{"echo", "one", "two"},
+		},
+		{
+			Line: "plain command",
+			Args: []string{"plain", "command"},
+		},
+		{
+			Time: time.Unix(1600000020, 0),
+			Line: "echo café",
+			Args: []string{"echo", "café"},
+		},
+	}
+
+	got, err := shlex.ParseZshHistory(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("ParseZshHistory = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseZshHistory = %#v, want %#v", got, want)
+	}
+
+	if _, err := shlex.ParseZshHistory(strings.NewReader(": bogus:0;ls\n")); err == nil {
+		t.Errorf("ParseZshHistory(bogus timestamp) = nil, want error")
+	}
+}
@@ -0,0 +1,378 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+// lexAll reads all words from l, returning them and the final error.
+func lexAll(l *shlex.Lexer) ([]string, error) {
+	got := []string{}
+	for {
+		w, err := l.Next()
+		if err != nil {
+			return got, err
+		}
+		got = append(got, w)
+	}
+}
+
+func TestLexerNext(t *testing.T) {
+	for i, tt := range []struct {
+		in      string
+		want    []string
+		wantErr error
+	}{
+		{
+			in:      "one two \"three four\" # five\nsix",
+			want:    []string{"one", "two", "three four", "six"},
+			wantErr: io.EOF,
+		},
+		{
+			in:      "",
+			want:    []string{},
+			wantErr: io.EOF,
+		},
+		{
+			in:      "stuff 'more stuff",
+			want:    []string{"stuff", "more stuff"},
+			wantErr: shlex.ErrUnterminatedSingleQuote,
+		},
+		{
+			in:      `stuff "more \"stuff`,
+			want:    []string{"stuff", `more "stuff`},
+			wantErr: shlex.ErrUnterminatedDoubleQuote,
+		},
+		{
+			in:      `stuff\`,
+			want:    []string{"stuff"},
+			wantErr: shlex.ErrTrailingBackslash,
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.in), func(t *testing.T) {
+			l := shlex.NewLexer(strings.NewReader(tt.in))
+			got, err := lexAll(l)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Next = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Next = %#v, want %#v", got, tt.want)
+			}
+
+			// Errors are sticky.
+			if _, err2 := l.Next(); err2 != err {
+				t.Errorf("Next after error = %v, want %v", err2, err)
+			}
+		})
+	}
+}
+
+func TestLexerNextContext(t *testing.T) {
+	r, w := io.Pipe()
+	defer r.Close()
+	l := shlex.NewLexer(r)
+
+	go func() {
+		_, _ = io.WriteString(w, "first ")
+	}()
+	if got, err := l.NextContext(context.Background()); err != nil || got != "first" {
+		t.Fatalf("NextContext = (%q, %v), want (first, nil)", got, err)
+	}
+
+	// Nothing more is written, so the read blocks until the deadline.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.NextContext(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("NextContext = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// The abandoned read picks up the word once it arrives.
+	go func() {
+		_, _ = io.WriteString(w, "'second word' ")
+		w.Close()
+	}()
+	if got, err := l.Next(); err != nil || got != "second word" {
+		t.Fatalf("Next = (%q, %v), want (second word, nil)", got, err)
+	}
+	if _, err := l.NextContext(context.Background()); err != io.EOF {
+		t.Fatalf("NextContext = %v, want EOF", err)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := l.NextContext(canceled); err != context.Canceled {
+		t.Fatalf("NextContext = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestLexerResetAfterNextContext(t *testing.T) {
+	r, w := io.Pipe()
+	defer r.Close()
+	l := shlex.NewLexer(r)
+
+	// Nothing is written, so the read is abandoned at the deadline.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.NextContext(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("NextContext = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	l.Reset(strings.NewReader("one 'two three' four"))
+	if w, err := l.Next(); err != nil || w != "one" || l.Raw() != "one" {
+		t.Fatalf("Next = (%q, %v), Raw %q, want one", w, err, l.Raw())
+	}
+
+	// The abandoned read finishes with the old input while the new one is
+	// read, without touching it.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = io.WriteString(w, "'stale word' ")
+		w.Close()
+	}()
+	got, err := lexAll(l)
+	<-done
+	if err != io.EOF || !reflect.DeepEqual(got, []string{"two three", "four"}) || l.Raw() != "four" {
+		t.Errorf("Next = %q, %v, Raw %q, want [two three four], EOF", got, err, l.Raw())
+	}
+	time.Sleep(10 * time.Millisecond)
+	if _, err := l.Next(); err != io.EOF {
+		t.Errorf("Next = %v, want EOF", err)
+	}
+}
+
+// endlessReader yields an endless word, calling cancel after n bytes.
+type endlessReader struct {
+	n      int
+	cancel func()
+}
+
+func (r *endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 'a'
+	}
+	if r.n -= len(p); r.n <= 0 && r.cancel != nil {
+		r.cancel()
+		r.cancel = nil
+	}
+	return len(p), nil
+}
+
+func TestLexerWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	l := shlex.NewLexer(io.MultiReader(strings.NewReader("first "), &endlessReader{n: 1 << 20, cancel: cancel}), shlex.WithContext(ctx))
+	if got, err := l.Next(); err != nil || got != "first" {
+		t.Fatalf("Next = (%q, %v), want (first, nil)", got, err)
+	}
+	// The second word never ends, but scanning it stops once ctx is done.
+	if _, err := l.Next(); err != context.Canceled {
+		t.Fatalf("Next = %v, want %v", err, context.Canceled)
+	}
+	if _, err := l.Next(); err != context.Canceled {
+		t.Fatalf("Next after cancel = %v, want %v", err, context.Canceled)
+	}
+
+	if _, err := shlex.Lex("a b c", shlex.WithContext(ctx)); err != context.Canceled {
+		t.Errorf("Lex = %v, want %v", err, context.Canceled)
+	}
+	if got := shlex.Split("a b c", shlex.WithContext(context.Background())); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("Split = %q, want [a b c]", got)
+	}
+}
+
+func TestLexerPos(t *testing.T) {
+	in := "one  'two\nthree'\n\tfour # x\nこん five"
+	l := shlex.NewLexer(strings.NewReader(in), shlex.WithSource("a.conf"))
+
+	for _, want := range []struct {
+		word   string
+		pos    string
+		off    int
+		end    string
+		endOff int
+	}{
+		{word: "one", pos: "a.conf:1:1", off: 0, end: "a.conf:1:4", endOff: 3},
+		{word: "two\nthree", pos: "a.conf:1:6", off: 5, end: "a.conf:2:7", endOff: 16},
+		{word: "four", pos: "a.conf:3:2", off: 18, end: "a.conf:3:6", endOff: 22},
+		{word: "こん", pos: "a.conf:4:1", off: 27, end: "a.conf:4:3", endOff: 33},
+		{word: "five", pos: "a.conf:4:4", off: 34, end: "a.conf:4:8", endOff: 38},
+	} {
+		got, err := l.Next()
+		if err != nil {
+			t.Fatalf("Next = %v", err)
+		}
+		if got != want.word || l.Pos().String() != want.pos || l.Pos().Offset != want.off {
+			t.Errorf("Next = %q at %s (offset %d), want %q at %s (offset %d)", got, l.Pos(), l.Pos().Offset, want.word, want.pos, want.off)
+		}
+		if l.End().String() != want.end || l.End().Offset != want.endOff {
+			t.Errorf("%q ends at %s (offset %d), want %s (offset %d)", got, l.End(), l.End().Offset, want.end, want.endOff)
+		}
+	}
+}
+
+func TestLexerParseError(t *testing.T) {
+	l := shlex.NewLexer(strings.NewReader("ok\nstill ok \"not\nok"), shlex.WithSource("b.conf"))
+	_, err := lexAll(l)
+
+	var pe *shlex.ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("Next = %v, want *ParseError", err)
+	}
+	if want := "b.conf:2:10: unterminated double quote"; pe.Error() != want {
+		t.Errorf("Next = %q, want %q", pe.Error(), want)
+	}
+	if !errors.Is(err, shlex.ErrUnterminatedDoubleQuote) {
+		t.Errorf("Next = %v, want %v", err, shlex.ErrUnterminatedDoubleQuote)
+	}
+}
+
+func TestLexerStrictErrors(t *testing.T) {
+	for i, tt := range []struct {
+		in      string
+		strict  bool
+		want    []string
+		wantErr error
+	}{
+		{in: `a "b c`, strict: true, want: []string{"a", "b c"}, wantErr: shlex.ErrUnterminatedDoubleQuote},
+		{in: `a "b c`, want: []string{"a", "b c"}, wantErr: io.EOF},
+		{in: `a 'b`, want: []string{"a", "b"}, wantErr: io.EOF},
+		{in: `a b\`, want: []string{"a", "b"}, wantErr: io.EOF},
+		{in: `a b`, want: []string{"a", "b"}, wantErr: io.EOF},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.in), func(t *testing.T) {
+			got, err := lexAll(shlex.NewLexer(strings.NewReader(tt.in), shlex.WithStrictErrors(tt.strict)))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Next = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Next = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLexerRaw(t *testing.T) {
+	in := "cp  'my file' \"b\\\"c\"\\ d # note\n\xff\xfeé$x\n"
+	l := shlex.NewLexer(iotest.OneByteReader(strings.NewReader(in)))
+	var got []string
+	for {
+		_, err := l.Next()
+		if err != nil {
+			break
+		}
+		got = append(got, l.Raw())
+		if raw := in[l.Pos().Offset:l.End().Offset]; raw != l.Raw() {
+			t.Errorf("Raw = %q, want %q", l.Raw(), raw)
+		}
+	}
+	want := []string{"cp", "'my file'", `"b\"c"\ d`, "\xff\xfeé$x"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Raw = %q, want %q", got, want)
+	}
+}
+
+func TestLexerReset(t *testing.T) {
+	l := shlex.NewLexer(strings.NewReader(`a "b`), shlex.WithOperators(true))
+	if _, err := lexAll(l); !errors.Is(err, shlex.ErrUnterminatedDoubleQuote) {
+		t.Fatalf("Next = %v, want %v", err, shlex.ErrUnterminatedDoubleQuote)
+	}
+
+	for i, in := range []string{"x|y 'z w'", "second\nline", ""} {
+		l.Reset(strings.NewReader(in))
+		got, err := lexAll(l)
+		if err != io.EOF {
+			t.Errorf("Reset %d: Next = %v, want io.EOF", i, err)
+		}
+		if want := shlex.Split(in, shlex.WithOperators(true)); !reflect.DeepEqual(got, want) {
+			t.Errorf("Reset %d: Next = %#v, want %#v", i, got, want)
+		}
+	}
+
+	// Reset in the middle of the input.
+	l.Reset(strings.NewReader("one two"))
+	if w, _ := l.Next(); w != "one" {
+		t.Fatalf("Next = %q, want one", w)
+	}
+	l.Reset(strings.NewReader("  three"))
+	if w, err := l.Next(); w != "three" || l.Pos().Offset != 2 || l.Raw() != "three" {
+		t.Errorf("Next = %q, %v at %v (%q), want three at offset 2", w, err, l.Pos(), l.Raw())
+	}
+
+	var zero shlex.Lexer
+	zero.Reset(strings.NewReader("a|b"))
+	if got, _ := lexAll(&zero); !reflect.DeepEqual(got, []string{"a|b"}) {
+		t.Errorf("zero Lexer: Next = %#v, want [a|b]", got)
+	}
+}
+
+func TestLexerResetAllocs(t *testing.T) {
+	const in = "some 'quoted words' and \"more $x\""
+	l := shlex.NewLexer(strings.NewReader(in))
+	reused := testing.AllocsPerRun(100, func() {
+		l.Reset(strings.NewReader(in))
+		lexAll(l)
+	})
+	fresh := testing.AllocsPerRun(100, func() {
+		lexAll(shlex.NewLexer(strings.NewReader(in)))
+	})
+	if reused >= fresh {
+		t.Errorf("Reset: %v allocations, want fewer than NewLexer's %v", reused, fresh)
+	}
+}
+
+func TestLexerRuneScanner(t *testing.T) {
+	br := bufio.NewReader(strings.NewReader("PUT 'a b'\xff\tc\nbinary\x00payload"))
+	l := shlex.NewLexer(br)
+	var got, raw []string
+	for i := 0; i < 3; i++ {
+		w, err := l.Next()
+		if err != nil {
+			t.Fatalf("Next = %v", err)
+		}
+		got = append(got, w)
+		raw = append(raw, l.Raw())
+	}
+	if want := []string{"PUT", "a b\xff", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Next = %q, want %q", got, want)
+	}
+	if want := []string{"PUT", "'a b'\xff", "c"}; !reflect.DeepEqual(raw, want) {
+		t.Errorf("Raw = %q, want %q", raw, want)
+	}
+	if end := l.End(); end.Offset != 12 || end.Line != 1 {
+		t.Errorf("End = %+v, want offset 12 on line 1", end)
+	}
+
+	// The reader continues right after the last word.
+	rest, err := io.ReadAll(br)
+	if err != nil || string(rest) != "\nbinary\x00payload" {
+		t.Errorf("rest = %q, %v, want %q", rest, err, "\nbinary\x00payload")
+	}
+
+	// Handing the reader back and forth.
+	br = bufio.NewReader(strings.NewReader("a b\nline\nc"))
+	l = shlex.NewLexer(br)
+	w1, _ := l.Next()
+	w2, _ := l.Next()
+	line, _ := br.ReadString('\n')
+	line, _ = br.ReadString('\n')
+	l.Reset(br)
+	w3, _ := l.Next()
+	if w1 != "a" || w2 != "b" || line != "line\n" || w3 != "c" {
+		t.Errorf("got %q %q %q %q, want a b line c", w1, w2, line, w3)
+	}
+}
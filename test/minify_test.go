@@ -0,0 +1,103 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestMinify(t *testing.T) {
+	// The results are checked by bash, if it is installed.
+	bash, _ := exec.LookPath("bash")
+
+	for i, tt := range []struct {
+		desc    string
+		in      string
+		want    string
+		wantErr error
+	}{
+		{
+			desc: "lines",
+			in:   "#!/bin/sh\n# Greet.\n\nset -e\necho   'hello  world'  # comment\n\n",
+			want: "set -e; echo 'hello  world'",
+		},
+		{
+			desc: "continuations",
+			in:   "make \\\n  CFLAGS=\"-O2 \\\n-g\" \\\n  all\necho '\\'\n",
+			want: `make CFLAGS="-O2 -g" all; echo '\'`,
+		},
+		{
+			desc: "operators",
+			in:   "ls |\n  wc -l &&\necho ok 2>/dev/null\nsleep 1 &\nwait;\nx=$(a; b)",
+			want: "ls | wc -l && echo ok 2>/dev/null; sleep 1 & wait; x=$(a; b)",
+		},
+		{
+			desc: "if",
+			in:   "if [ -f x ]\nthen\n  rm x\nelif true; then\n  :\nelse\n  echo then\nfi\nnext",
+			want: "if [ -f x ]; then rm x; elif true; then :; else echo then; fi; next",
+		},
+		{
+			desc: "loops",
+			in:   "for f in *.go\ndo\n  gofmt -l \"$f\"\ndone\nwhile true; do { a\n b\n }\ndone",
+			want: `for f in *.go; do gofmt -l "$f"; done; while true; do { a; b; }; done`,
+		},
+		{
+			desc: "case",
+			in:   "case $1 in\n  a|b)\n    echo ab\n    ;;\n  *) echo other;;\nesac\nnext\ncase x in\n y) z\nesac",
+			want: "case $1 in a|b) echo ab; ;; *) echo other;; esac; next; case x in y) z; esac",
+		},
+		{
+			desc: "subshell",
+			in:   "(\n cd /tmp\n ls\n)\necho",
+			want: "( cd /tmp; ls; ); echo",
+		},
+		{
+			desc: "functions",
+			in:   "f() {\n  echo hi\n}\nf\nfunction g\n{\n  :\n}\nh ()\n{ :; }\nfunction i() {\n  :\n}",
+			want: "f() { echo hi; }; f; function g { :; }; h () { :; }; function i() { :; }",
+		},
+		{
+			desc: "escaped blank",
+			in:   "echo a\\ #b \\\n  c # d \\\ne",
+			want: `echo a\ #b c; e`,
+		},
+		{
+			desc:    "quoted newline",
+			in:      "echo 'a\nb'",
+			wantErr: shlex.ErrQuotedNewline,
+		},
+		{
+			desc:    "heredoc",
+			in:      "cat <<EOF\nhi\nEOF\n",
+			wantErr: shlex.ErrHeredoc,
+		},
+		{
+			desc:    "unterminated",
+			in:      "echo 'a",
+			wantErr: shlex.ErrUnterminatedSingleQuote,
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			got, err := shlex.Minify(tt.in)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Minify = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Minify = %q, want %q", got, tt.want)
+			}
+			if err != nil || bash == "" {
+				return
+			}
+			if out, err := exec.Command(bash, "-n", "-c", got).CombinedOutput(); err != nil {
+				t.Errorf("bash -n -c %q: %v\n%s", got, err, out)
+			}
+		})
+	}
+}
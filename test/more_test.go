@@ -0,0 +1,83 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestSplitMore(t *testing.T) {
+	for i, tt := range []struct {
+		desc      string
+		line      string
+		more      []string
+		want      []string
+		wantCalls int
+		wantErr   error
+	}{
+		{
+			desc: "complete",
+			line: "echo 'a b'",
+			more: []string{"unused"},
+			want: []string{"echo", "a b"},
+		},
+		{
+			desc:      "open quote",
+			line:      "echo 'a",
+			more:      []string{"b", "c' d"},
+			want:      []string{"echo", "a\nb\nc", "d"},
+			wantCalls: 2,
+		},
+		{
+			desc:      "continuation",
+			line:      `ls -l \`,
+			more:      []string{`  /tmp \`, "/var"},
+			want:      []string{"ls", "-l", "/tmp", "/var"},
+			wantCalls: 2,
+		},
+		{
+			desc:      "continuation within word",
+			line:      `ec\`,
+			more:      []string{"ho hi"},
+			want:      []string{"echo", "hi"},
+			wantCalls: 1,
+		},
+		{
+			desc:      "end of input",
+			line:      `echo "a`,
+			more:      []string{"b"},
+			want:      []string{"echo", "a\nb"},
+			wantCalls: 2,
+			wantErr:   io.EOF,
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			calls := 0
+			more := func() (string, error) {
+				calls++
+				if calls > len(tt.more) {
+					return "", io.EOF
+				}
+				return tt.more[calls-1], nil
+			}
+			got, err := shlex.SplitMore(tt.line, more)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SplitMore = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitMore = %#v, want %#v", got, tt.want)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("more called %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
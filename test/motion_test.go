@@ -0,0 +1,107 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestWordMotions(t *testing.T) {
+	line := `cp 'my file' "b c"  d`
+	for i, tt := range []struct {
+		cursor    int
+		wantStart int
+		wantEnd   int
+	}{
+		{cursor: 0, wantStart: 0, wantEnd: 2},
+		{cursor: 2, wantStart: 0, wantEnd: 12},
+		{cursor: 3, wantStart: 0, wantEnd: 12},
+		{cursor: 6, wantStart: 3, wantEnd: 12},
+		{cursor: 12, wantStart: 3, wantEnd: 18},
+		{cursor: 19, wantStart: 13, wantEnd: 21},
+		{cursor: 21, wantStart: 20, wantEnd: 21},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %d", i, tt.cursor), func(t *testing.T) {
+			if got := shlex.PrevWordStart(line, tt.cursor); got != tt.wantStart {
+				t.Errorf("PrevWordStart = %d, want %d", got, tt.wantStart)
+			}
+			if got := shlex.NextWordEnd(line, tt.cursor); got != tt.wantEnd {
+				t.Errorf("NextWordEnd = %d, want %d", got, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestDeleteWordBackward(t *testing.T) {
+	for i, tt := range []struct {
+		line       string
+		cursor     int
+		want       string
+		wantCursor int
+	}{
+		{line: `cp 'my file' x`, cursor: 12, want: `cp  x`, wantCursor: 3},
+		{line: `cp 'my file' x`, cursor: 13, want: `cp x`, wantCursor: 3},
+		{line: `echo "a b`, cursor: 9, want: `echo `, wantCursor: 5},
+		{line: `   `, cursor: 2, want: ` `, wantCursor: 0},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.line), func(t *testing.T) {
+			got, cursor := shlex.DeleteWordBackward(tt.line, tt.cursor)
+			if got != tt.want || cursor != tt.wantCursor {
+				t.Errorf("DeleteWordBackward = (%q, %d), want (%q, %d)", got, cursor, tt.want, tt.wantCursor)
+			}
+		})
+	}
+}
+
+func TestTransposeWords(t *testing.T) {
+	for i, tt := range []struct {
+		line       string
+		cursor     int
+		want       string
+		wantCursor int
+	}{
+		{line: `mv 'a b'  c`, cursor: 3, want: `'a b' mv  c`, wantCursor: 8},
+		{line: `mv 'a b'  c`, cursor: 9, want: `mv c  'a b'`, wantCursor: 11},
+		{line: `mv 'a b'  c`, cursor: 11, want: `mv c  'a b'`, wantCursor: 11},
+		{line: `mv 'a b'  c`, cursor: 0, want: `'a b' mv  c`, wantCursor: 8},
+		{line: `mv`, cursor: 1, want: `mv`, wantCursor: 1},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.line), func(t *testing.T) {
+			got, cursor := shlex.TransposeWords(tt.line, tt.cursor)
+			if got != tt.want || cursor != tt.wantCursor {
+				t.Errorf("TransposeWords = (%q, %d), want (%q, %d)", got, cursor, tt.want, tt.wantCursor)
+			}
+		})
+	}
+}
+
+func TestWordAt(t *testing.T) {
+	for i, tt := range []struct {
+		line      string
+		cursor    int
+		want      string
+		wantStart int
+		wantEnd   int
+	}{
+		{line: `cp 'my file' b\ c`, cursor: 0, want: "cp", wantStart: 0, wantEnd: 2},
+		{line: `cp 'my file' b\ c`, cursor: 2, want: "cp", wantStart: 0, wantEnd: 2},
+		{line: `cp 'my file' b\ c`, cursor: 6, want: "my file", wantStart: 3, wantEnd: 12},
+		{line: `cp 'my file' b\ c`, cursor: 15, want: "b c", wantStart: 13, wantEnd: 17},
+		{line: `cp  x`, cursor: 3, want: "", wantStart: 3, wantEnd: 3},
+		{line: `ls 'My Doc`, cursor: 10, want: "My Doc", wantStart: 3, wantEnd: 10},
+		{line: `ls "a b" `, cursor: 9, want: "", wantStart: 9, wantEnd: 9},
+		{line: ``, cursor: 0, want: "", wantStart: 0, wantEnd: 0},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s/%d", i, tt.line, tt.cursor), func(t *testing.T) {
+			got, start, end := shlex.WordAt(tt.line, tt.cursor)
+			if got != tt.want || start != tt.wantStart || end != tt.wantEnd {
+				t.Errorf("WordAt = %q, %d, %d, want %q, %d, %d", got, start, end, tt.want, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
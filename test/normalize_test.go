@@ -0,0 +1,126 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestNormalizeEscapes(t *testing.T) {
+	for i, tt := range []struct {
+		in           string
+		want         string
+		wantRewrites []shlex.Rewrite
+	}{
+		{
+			in:   "echo hello",
+			want: "echo hello",
+		},
+		{
+			in:   `Escaped \e Character\.txt`,
+			want: `Escaped e Character.txt`,
+			wantRewrites: []shlex.Rewrite{
+				{Offset: 8, Old: `\e`, New: "e"},
+				{Offset: 20, Old: `\.`, New: "."},
+			},
+		},
+		{
+			in:   `keep\ space \$HOME \* \# a\=b \\`,
+			want: `keep\ space \$HOME \* \# a\=b \\`,
+		},
+		{
+			in:   `'\e' "\e" '\"'`,
+			want: `'\e' "\e" '\"'`,
+		},
+		{
+			in:   `echo a""b c'' '' "" ""#x`,
+			want: `echo ab c '' "" ""#x`,
+			wantRewrites: []shlex.Rewrite{
+				{Offset: 6, Old: `""`},
+				{Offset: 11, Old: `''`},
+			},
+		},
+		{
+			in:   `echo \x # not \y`,
+			want: `echo x # not \y`,
+			wantRewrites: []shlex.Rewrite{
+				{Offset: 5, Old: `\x`, New: "x"},
+			},
+		},
+		{
+			in:   `echo $""b $''c $\d \$""e`,
+			want: `echo $""b $''c $\d \$""e`,
+		},
+		{
+			in:   `ls ~''/x ~r\oot a''~''b/c''d`,
+			want: `ls ~''/x ~r\oot a~''b/cd`,
+			wantRewrites: []shlex.Rewrite{
+				{Offset: 17, Old: "''"},
+				{Offset: 25, Old: "''"},
+			},
+		},
+		{
+			in:   `FOO''=1 B\AR=2 X""+=3 \Y=4 a''=b 1''=c cmd a""b`,
+			want: `FOO''=1 B\AR=2 X""+=3 \Y=4 a''=b 1=c cmd ab`,
+			wantRewrites: []shlex.Rewrite{
+				{Offset: 34, Old: "''"},
+				{Offset: 44, Old: `""`},
+			},
+		},
+		{
+			in:   `\ls \x; i\f \y l''s; FOO=1 \z \w`,
+			want: `\ls x; i\f y ls; FOO=1 \z w`,
+			wantRewrites: []shlex.Rewrite{
+				{Offset: 4, Old: `\x`, New: "x"},
+				{Offset: 12, Old: `\y`, New: "y"},
+				{Offset: 16, Old: "''"},
+				{Offset: 30, Old: `\w`, New: "w"},
+			},
+		},
+		{
+			in:   "a \\b\nl''s",
+			want: "a b\nl''s",
+			wantRewrites: []shlex.Rewrite{
+				{Offset: 2, Old: `\b`, New: "b"},
+			},
+		},
+		{
+			in:   `echo a"`,
+			want: `echo a"`,
+		},
+		{
+			in:   `0"`,
+			want: `0"`,
+		},
+		{
+			in:   `echo \a'`,
+			want: `echo a'`,
+			wantRewrites: []shlex.Rewrite{
+				{Offset: 5, Old: `\a`, New: "a"},
+			},
+		},
+		{
+			in:   `"unterminated \e`,
+			want: `"unterminated \e`,
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.in), func(t *testing.T) {
+			got, rewrites := shlex.NormalizeEscapes(tt.in)
+			if got != tt.want {
+				t.Errorf("NormalizeEscapes = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(rewrites, tt.wantRewrites) {
+				t.Errorf("NormalizeEscapes rewrites = %#v, want %#v", rewrites, tt.wantRewrites)
+			}
+			if a, b := shlex.Split(tt.in), shlex.Split(got); !reflect.DeepEqual(a, b) {
+				t.Errorf("Split changed from %#v to %#v", a, b)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestValidateAgainstPolicy(t *testing.T) {
+	strict := shlex.Policy{
+		Commands: map[string][]string{
+			"ls":    {"-l", "-a", "--color"},
+			"echo":  nil,
+			"grep":  {"-i"},
+			"uname": {},
+		},
+	}
+	loose := strict
+	loose.AllowExpansions = true
+	loose.AllowOperators = true
+
+	for i, tt := range []struct {
+		desc     string
+		in       string
+		policy   shlex.Policy
+		want     error
+		wantWord string
+	}{
+		{desc: "allowed", in: "ls -l /tmp", policy: strict},
+		{desc: "empty", in: "  # nothing", policy: strict},
+		{desc: "cluster", in: "ls -la --color=auto", policy: strict},
+		{desc: "any flags", in: "echo -n -e hi", policy: strict},
+		{desc: "after double dash", in: "ls -- -rf", policy: strict},
+		{desc: "quoted operator", in: "echo 'a|b' \\>", policy: strict},
+		{
+			desc:     "command",
+			in:       "rm -rf /",
+			policy:   strict,
+			want:     shlex.ErrCommandNotAllowed,
+			wantWord: "rm",
+		},
+		{
+			desc:     "path",
+			in:       "/bin/ls",
+			policy:   strict,
+			want:     shlex.ErrCommandNotAllowed,
+			wantWord: "/bin/ls",
+		},
+		{
+			desc:     "assignment",
+			in:       "LD_PRELOAD=x ls",
+			policy:   strict,
+			want:     shlex.ErrCommandNotAllowed,
+			wantWord: "LD_PRELOAD=x",
+		},
+		{
+			desc:     "flag",
+			in:       "ls -l -R",
+			policy:   strict,
+			want:     shlex.ErrFlagNotAllowed,
+			wantWord: "-R",
+		},
+		{
+			desc:     "flag in cluster",
+			in:       "ls -lR",
+			policy:   strict,
+			want:     shlex.ErrFlagNotAllowed,
+			wantWord: "-lR",
+		},
+		{
+			desc:     "no flags",
+			in:       "uname -a",
+			policy:   strict,
+			want:     shlex.ErrFlagNotAllowed,
+			wantWord: "-a",
+		},
+		{
+			desc:     "variable",
+			in:       `echo "$HOME"`,
+			policy:   strict,
+			want:     shlex.ErrExpansionNotAllowed,
+			wantWord: "$HOME",
+		},
+		{desc: "comment", in: "echo hi # $(id)", policy: strict},
+		{
+			desc:     "escaped blank before hash",
+			in:       `echo a\ #$(id)`,
+			policy:   strict,
+			want:     shlex.ErrExpansionNotAllowed,
+			wantWord: "$(id)",
+		},
+		{
+			desc:     "escaped quote in ansi-c quotes",
+			in:       `echo $'\'' $(id) ''`,
+			policy:   strict,
+			want:     shlex.ErrExpansionNotAllowed,
+			wantWord: "$(id)",
+		},
+		{
+			desc:     "substitution across words",
+			in:       "echo $(id -u)",
+			policy:   strict,
+			want:     shlex.ErrExpansionNotAllowed,
+			wantWord: "$(id -u)",
+		},
+		{
+			desc:     "glob",
+			in:       "ls *.go",
+			policy:   strict,
+			want:     shlex.ErrExpansionNotAllowed,
+			wantWord: "*.go",
+		},
+		{
+			desc:     "tilde",
+			in:       "ls ~root",
+			policy:   strict,
+			want:     shlex.ErrExpansionNotAllowed,
+			wantWord: "~root",
+		},
+		{
+			desc:     "pipe",
+			in:       "ls|grep x",
+			policy:   strict,
+			want:     shlex.ErrOperatorNotAllowed,
+			wantWord: "|",
+		},
+		{desc: "loose", in: "ls -a $HOME/*.go | grep -i x > out", policy: loose},
+		{
+			desc:     "command after operator",
+			in:       "echo hi; rm x",
+			policy:   loose,
+			want:     shlex.ErrCommandNotAllowed,
+			wantWord: "rm",
+		},
+		{
+			desc:     "flag after pipe",
+			in:       "ls | grep -v x",
+			policy:   loose,
+			want:     shlex.ErrFlagNotAllowed,
+			wantWord: "-v",
+		},
+		{
+			desc:   "unterminated",
+			in:     "echo 'x",
+			policy: strict,
+			want:   shlex.ErrUnterminatedSingleQuote,
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			err := shlex.ValidateAgainstPolicy(tt.in, tt.policy)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("ValidateAgainstPolicy = %v, want %v", err, tt.want)
+			}
+			if len(tt.wantWord) == 0 {
+				return
+			}
+			var pe *shlex.PolicyError
+			if !errors.As(err, &pe) {
+				t.Fatalf("ValidateAgainstPolicy = %T, want *PolicyError", err)
+			}
+			if pe.Word != tt.wantWord || tt.in[pe.Offset:pe.Offset+len(pe.Word)] != pe.Word {
+				t.Errorf("ValidateAgainstPolicy = %q at %d, want %q", pe.Word, pe.Offset, tt.wantWord)
+			}
+		})
+	}
+}
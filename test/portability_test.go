@@ -0,0 +1,95 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestCheckPortability(t *testing.T) {
+	type diag struct {
+		offset int
+		text   string
+	}
+	for i, tt := range []struct {
+		desc string
+		in   string
+		want []diag
+	}{
+		{
+			desc: "portable",
+			in:   `for f in *.c; do cc -c "$f" >/dev/null 2>&1; done`,
+		},
+		{
+			desc: "ansi-c quoting",
+			in:   `printf $'a\'b\n' x`,
+			want: []diag{{7, `$'a\'b\n'`}},
+		},
+		{
+			desc: "locale quoting",
+			in:   `echo $"hello $USER"`,
+			want: []diag{{5, `$"`}},
+		},
+		{
+			desc: "process substitution",
+			in:   "diff <(sort a) >(cat)",
+			want: []diag{{5, "<(sort a)"}, {15, ">(cat)"}},
+		},
+		{
+			desc: "redirect both",
+			in:   "make &>log; make &>>log",
+			want: []diag{{5, "&>"}, {17, "&>>"}},
+		},
+		{
+			desc: "here-string",
+			in:   "cat <<<hi",
+			want: []diag{{4, "<<<"}},
+		},
+		{
+			desc: "double bracket",
+			in:   `[[ -n "$x" ]] && echo`,
+			want: []diag{{0, "[["}},
+		},
+		{
+			desc: "arrays",
+			in:   `a=(1 2) b=x; echo "${a[1]}"`,
+			want: []diag{{0, "a=("}, {19, "${a[1]}"}},
+		},
+		{
+			desc: "brace expansion",
+			in:   "cp f{,.bak} {1..3} {x} {}",
+			want: []diag{{4, "{,.bak}"}, {12, "{1..3}"}},
+		},
+		{
+			desc: "quoted and escaped",
+			in:   `echo '<(x) &>' "&> [[" \&> \$'x' # <(y)`,
+		},
+		{
+			desc: "escaped blank",
+			in:   `a\ #<(x)`,
+			want: []diag{{4, "<(x)"}},
+		},
+		{
+			desc: "escaped and quoted blanks",
+			in:   "x\\ [[ y\\ a=(1) \"z \"b=(2) # c\n[[ -f x ]]",
+			want: []diag{{29, "[["}},
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			got := shlex.CheckPortability(tt.in)
+			if len(got) != len(tt.want) {
+				t.Fatalf("CheckPortability(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+			for j, d := range got {
+				if d.Offset != tt.want[j].offset || d.Text != tt.want[j].text || len(d.Message) == 0 {
+					t.Errorf("CheckPortability(%q)[%d] = %v, want %q at %d", tt.in, j, d, tt.want[j].text, tt.want[j].offset)
+				}
+			}
+		})
+	}
+}
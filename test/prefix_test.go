@@ -0,0 +1,81 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestHasPrefixUnquoted(t *testing.T) {
+	for i, tt := range []struct {
+		line   string
+		prefix string
+		want   bool
+	}{
+		{line: "ls -la", prefix: "ls", want: true},
+		{line: `"ls" -la`, prefix: "ls", want: true},
+		{line: `l\s -la`, prefix: "ls", want: true},
+		{line: "lsblk", prefix: "ls", want: false},
+		{line: "git 'commit' -m x", prefix: "git commit", want: true},
+		{line: "git", prefix: "git commit", want: false},
+		{line: "'git commit' -m x", prefix: "git commit", want: false},
+		{line: "anything", prefix: "", want: true},
+		{line: "", prefix: "ls", want: false},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.line), func(t *testing.T) {
+			if got := shlex.HasPrefixUnquoted(tt.line, tt.prefix); got != tt.want {
+				t.Errorf("HasPrefixUnquoted(%q, %q) = %v, want %v", tt.line, tt.prefix, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrimPrefixWord(t *testing.T) {
+	for i, tt := range []struct {
+		line   string
+		word   string
+		want   string
+		wantOK bool
+	}{
+		{line: "ls -la 'a b'", word: "ls", want: "-la 'a b'", wantOK: true},
+		{line: `  "ls"   -la`, word: "ls", want: "-la", wantOK: true},
+		{line: "'ls'", word: "ls", want: "", wantOK: true},
+		{line: "lsblk -a", word: "ls", want: "lsblk -a", wantOK: false},
+		{line: "", word: "ls", want: "", wantOK: false},
+		{line: "'' x", word: "", want: "x", wantOK: true},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.line), func(t *testing.T) {
+			got, ok := shlex.TrimPrefixWord(tt.line, tt.word)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("TrimPrefixWord(%q, %q) = (%q, %v), want (%q, %v)", tt.line, tt.word, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestSplitIndent(t *testing.T) {
+	for i, tt := range []struct {
+		line       string
+		wantIndent string
+		wantArgs   []string
+	}{
+		{line: "ls", wantIndent: "", wantArgs: []string{"ls"}},
+		{line: "  \tls 'a b'", wantIndent: "  \t", wantArgs: []string{"ls", "a b"}},
+		{line: "　echo", wantIndent: "　", wantArgs: []string{"echo"}},
+		{line: "   ", wantIndent: "   ", wantArgs: []string{}},
+		{line: "  # comment", wantIndent: "  ", wantArgs: []string{}},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %q", i, tt.line), func(t *testing.T) {
+			indent, args := shlex.SplitIndent(tt.line)
+			if indent != tt.wantIndent || !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("SplitIndent = (%q, %#v), want (%q, %#v)", indent, args, tt.wantIndent, tt.wantArgs)
+			}
+		})
+	}
+}
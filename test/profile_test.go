@@ -0,0 +1,82 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestProfiles(t *testing.T) {
+	for i, tt := range []struct {
+		in   string
+		opts []shlex.Option
+		want []string
+	}{
+		{in: "\"a\\\nb\" $'c' $\"d\"", opts: []shlex.Option{shlex.Bash}, want: []string{"ab", "c", "d"}},
+		{in: "\"a\\\nb\" $'c' $\"d\"", opts: []shlex.Option{shlex.POSIX}, want: []string{"ab", "$c", "$d"}},
+		{in: "$'' $\"\"", opts: []shlex.Option{shlex.POSIX}, want: []string{"$", "$"}},
+		{in: "a\\\nb \\\n c '\\\n'", opts: []shlex.Option{shlex.POSIX}, want: []string{"ab", "c", "\\\n"}},
+		{in: "a|&b &>c <<<d >|e", opts: []shlex.Option{shlex.POSIX, shlex.WithOperators(true)}, want: []string{"a", "|", "&", "b", "&", ">", "c", "<<", "<", "d", ">|", "e"}},
+		{in: "a|&b &>c <<<d", opts: []shlex.Option{shlex.Bash, shlex.WithOperators(true)}, want: []string{"a", "|&", "b", "&>", "c", "<<<", "d"}},
+		// Options after a profile change it, those before it do not.
+		{in: "a|b \"c\\\nd\"", opts: []shlex.Option{shlex.Bash, shlex.WithOperators(true)}, want: []string{"a", "|", "b", "cd"}},
+		{in: "a|b", opts: []shlex.Option{shlex.WithOperators(true), shlex.Bash}, want: []string{"a|b"}},
+		{
+			in:   "a;b #c",
+			opts: []shlex.Option{shlex.NewProfile("conf", shlex.WithTerminators(";"), shlex.WithComments(shlex.CommentLiteral))},
+			want: []string{"a", ";", "b", "#c"},
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %q", i, tt.in), func(t *testing.T) {
+			if got := shlex.Split(tt.in, tt.opts...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfileString(t *testing.T) {
+	for _, p := range []*shlex.Profile{shlex.Bash, shlex.POSIX, shlex.NewProfile("mine")} {
+		if p.String() == "" {
+			t.Errorf("Profile has no name")
+		}
+	}
+	if got := shlex.POSIX.String(); got != "posix" {
+		t.Errorf("POSIX.String() = %q, want posix", got)
+	}
+}
+
+func TestProfileConcurrent(t *testing.T) {
+	p := shlex.NewProfile("ops", shlex.WithOperators(true))
+	want := []string{"a", "&&", "b"}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if got := shlex.Split("a&&b", p); !reflect.DeepEqual(got, want) {
+					t.Errorf("Split = %#v, want %#v", got, want)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestProfileAllocs(t *testing.T) {
+	p := shlex.NewProfile("ops", shlex.WithOperators(true), shlex.WithStripCR(true))
+	base := testing.AllocsPerRun(100, func() { shlex.Split("a b") })
+	got := testing.AllocsPerRun(100, func() { shlex.Split("a b", p) })
+	if got > base {
+		t.Errorf("Split with a profile: %v allocations, want at most %v", got, base)
+	}
+}
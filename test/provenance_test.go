@@ -0,0 +1,153 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestExpandFields(t *testing.T) {
+	vars := map[string]string{
+		"USER": "me; rm -rf /",
+		"CMD":  "sh",
+	}
+	ex := &shlex.Expander{
+		Var: func(name string) (string, bool) {
+			v, ok := vars[name]
+			return v, ok
+		},
+		Subst: func(cmd string) (string, error) {
+			return "out\n", nil
+		},
+		Glob: func(pattern string) ([]string, error) {
+			if pattern == "*.go" {
+				return []string{"a.go"}, nil
+			}
+			return nil, nil
+		},
+	}
+
+	type part struct {
+		value  string
+		origin shlex.Origin
+	}
+	lit := shlex.OriginLiteral
+	param := shlex.OriginParameter
+
+	for i, tt := range []struct {
+		desc         string
+		in           string
+		want         [][]part
+		wantExpanded []bool
+	}{
+		{
+			desc:         "literal",
+			in:           `echo 'a b'`,
+			want:         [][]part{{{"echo", lit}}, {{"a b", lit}}},
+			wantExpanded: []bool{false, false},
+		},
+		{
+			desc: "split parameter",
+			in:   `echo x$USER`,
+			want: [][]part{
+				{{"echo", lit}},
+				{{"x", lit}, {"me;", param}},
+				{{"rm", param}},
+				{{"-rf", param}},
+				{{"/", param}},
+			},
+			wantExpanded: []bool{false, true, true, true, true},
+		},
+		{
+			desc: "quoted parameter",
+			in:   `echo "<$USER>"`,
+			want: [][]part{
+				{{"echo", lit}},
+				{{"<", lit}, {"me; rm -rf /", param}, {">", lit}},
+			},
+			wantExpanded: []bool{false, true},
+		},
+		{
+			desc:         "command name",
+			in:           `$CMD -c "$(true)"`,
+			want:         [][]part{{{"sh", param}}, {{"-c", lit}}, {{"out", shlex.OriginCommand}}},
+			wantExpanded: []bool{true, false, true},
+		},
+		{
+			desc:         "default word",
+			in:           `${UNSET:-x$CMD}`,
+			want:         [][]part{{{"x", lit}, {"sh", param}}},
+			wantExpanded: []bool{true},
+		},
+		{
+			desc:         "pathnames",
+			in:           `*.go *.txt`,
+			want:         [][]part{{{"a.go", shlex.OriginPathname}}, {{"*.txt", lit}}},
+			wantExpanded: []bool{true, false},
+		},
+		{
+			desc:         "empty",
+			in:           `'' "$UNSET"`,
+			want:         [][]part{nil, nil},
+			wantExpanded: []bool{false, false},
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			tokens, err := shlex.Lex(tt.in)
+			if err != nil {
+				t.Fatalf("Lex = %v", err)
+			}
+			fields, err := ex.ExpandFields(tokens)
+			if err != nil {
+				t.Fatalf("ExpandFields = %v", err)
+			}
+
+			var got [][]part
+			var gotExpanded []bool
+			for _, f := range fields {
+				var parts []part
+				value := ""
+				for _, p := range f.Parts {
+					parts = append(parts, part{p.Value, p.Origin})
+					value += p.Value
+				}
+				if value != f.Value {
+					t.Errorf("parts of %q concatenate to %q", f.Value, value)
+				}
+				got = append(got, parts)
+				gotExpanded = append(gotExpanded, f.Expanded())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExpandFields = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(gotExpanded, tt.wantExpanded) {
+				t.Errorf("Expanded = %v, want %v", gotExpanded, tt.wantExpanded)
+			}
+		})
+	}
+}
+
+func TestOriginString(t *testing.T) {
+	for i, tt := range []struct {
+		o    shlex.Origin
+		want string
+	}{
+		{shlex.OriginLiteral, "literal"},
+		{shlex.OriginParameter, "parameter"},
+		{shlex.OriginCommand, "command"},
+		{shlex.OriginPathname, "pathname"},
+		{shlex.Origin(9), "Origin(9)"},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.want), func(t *testing.T) {
+			if got := tt.o.String(); got != tt.want {
+				t.Errorf("String = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
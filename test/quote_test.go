@@ -0,0 +1,347 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestQuoteStyle(t *testing.T) {
+	for i, tt := range []struct {
+		desc  string
+		in    string
+		style shlex.Style
+		want  string
+	}{
+		{
+			desc:  "auto safe",
+			in:    "--foo=bar/baz.txt",
+			style: shlex.StyleAuto,
+			want:  "--foo=bar/baz.txt",
+		},
+		{
+			desc:  "auto space",
+			in:    "more stuff",
+			style: shlex.StyleAuto,
+			want:  "'more stuff'",
+		},
+		{
+			desc:  "auto empty",
+			in:    "",
+			style: shlex.StyleAuto,
+			want:  "''",
+		},
+		{
+			desc:  "auto embedded quote",
+			in:    "doesn't",
+			style: shlex.StyleAuto,
+			want:  `"doesn't"`,
+		},
+		{
+			desc:  "auto embedded quote and specials",
+			in:    "it's $5",
+			style: shlex.StyleAuto,
+			want:  `it\''s $5'`,
+		},
+		{
+			desc:  "auto quotes only",
+			in:    "''",
+			style: shlex.StyleAuto,
+			want:  `"''"`,
+		},
+		{
+			desc:  "auto leading quote and history expansion",
+			in:    "'hi!",
+			style: shlex.StyleAuto,
+			want:  `\''hi!'`,
+		},
+		{
+			desc:  "single forced",
+			in:    "stuff",
+			style: shlex.StyleSingle,
+			want:  "'stuff'",
+		},
+		{
+			desc:  "single embedded quote",
+			in:    "doesn't",
+			style: shlex.StyleSingle,
+			want:  `'doesn'\''t'`,
+		},
+		{
+			desc:  "double specials",
+			in:    "a $b `c` \"d\" \\e",
+			style: shlex.StyleDouble,
+			want:  "\"a \\$b \\`c\\` \\\"d\\\" \\\\e\"",
+		},
+		{
+			desc:  "bare",
+			in:    "more stuff's",
+			style: shlex.StyleBare,
+			want:  `more\ stuff\'s`,
+		},
+		{
+			desc:  "bare newline",
+			in:    "a\nb",
+			style: shlex.StyleBare,
+			want:  "a'\n'b",
+		},
+		{
+			desc:  "bare empty",
+			in:    "",
+			style: shlex.StyleBare,
+			want:  "''",
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			got := shlex.QuoteStyle(tt.in, tt.style)
+			if got != tt.want {
+				t.Errorf("QuoteStyle = %q, want %q", got, tt.want)
+			}
+			if split := shlex.Split(got); !reflect.DeepEqual(split, []string{tt.in}) {
+				t.Errorf("Split(QuoteStyle) = %#v, want %#v", split, []string{tt.in})
+			}
+		})
+	}
+}
+
+func TestQuoteStyleANSIC(t *testing.T) {
+	for i, tt := range []struct {
+		desc string
+		in   string
+		want string
+	}{
+		{
+			desc: "printable",
+			in:   "it's héllo",
+			want: `"it's héllo"`,
+		},
+		{
+			desc: "safe",
+			in:   "plain",
+			want: "plain",
+		},
+		{
+			desc: "named escapes",
+			in:   "a\tb\nit's \\ \x1b[0m",
+			want: `$'a\tb\nit\'s \\ \e[0m'`,
+		},
+		{
+			desc: "other control characters",
+			in:   "\x00\x7f1\u200b2\U000e0001",
+			want: `$'\x00\x7f1\u200b2\U000e0001'`,
+		},
+		{
+			desc: "invalid UTF-8",
+			in:   "caf\xe9!",
+			want: `$'caf\xe9!'`,
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			if got := shlex.QuoteStyle(tt.in, shlex.StyleANSIC); got != tt.want {
+				t.Errorf("QuoteStyle = %q, want %q", got, tt.want)
+			}
+			if got := shlex.QuoteANSIC(tt.in); got != tt.want {
+				t.Errorf("QuoteANSIC = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuoteAlways(t *testing.T) {
+	for i, tt := range []struct {
+		in   string
+		want string
+	}{
+		{in: "stuff", want: "'stuff'"},
+		{in: "", want: "''"},
+		{in: "it's", want: `'it'\''s'`},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.in), func(t *testing.T) {
+			got := shlex.QuoteAlways(tt.in)
+			if got != tt.want {
+				t.Errorf("QuoteAlways = %q, want %q", got, tt.want)
+			}
+			if split := shlex.Split(got); !reflect.DeepEqual(split, []string{tt.in}) {
+				t.Errorf("Split(QuoteAlways) = %#v, want %#v", split, []string{tt.in})
+			}
+		})
+	}
+}
+
+func TestQuoteDialect(t *testing.T) {
+	for i, tt := range []struct {
+		in      string
+		dialect shlex.Dialect
+		want    string
+	}{
+		{in: "safe", dialect: shlex.DialectPOSIX, want: "safe"},
+		{in: "it's $x", dialect: shlex.DialectBash, want: `it\''s $x'`},
+		{in: "=ls", dialect: shlex.DialectBash, want: "=ls"},
+		{in: "=ls", dialect: shlex.DialectZsh, want: "'=ls'"},
+		{in: "safe", dialect: shlex.DialectFish, want: "safe"},
+		{in: "%self", dialect: shlex.DialectFish, want: "'%self'"},
+		{in: "it's", dialect: shlex.DialectFish, want: `"it's"`},
+		{in: `a\b c`, dialect: shlex.DialectFish, want: `'a\\b c'`},
+		{in: `it's $HOME\`, dialect: shlex.DialectFish, want: `'it\'s $HOME\\'`},
+		{in: "", dialect: shlex.DialectFish, want: "''"},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s %s", i, tt.dialect, tt.in), func(t *testing.T) {
+			got := shlex.QuoteDialect(tt.in, tt.dialect)
+			if got != tt.want {
+				t.Errorf("QuoteDialect = %q, want %q", got, tt.want)
+			}
+			if tt.dialect == shlex.DialectFish {
+				return
+			}
+			if split := shlex.Split(got); !reflect.DeepEqual(split, []string{tt.in}) {
+				t.Errorf("Split(QuoteDialect) = %#v, want %#v", split, []string{tt.in})
+			}
+		})
+	}
+}
+
+func TestJoinDialect(t *testing.T) {
+	args := []string{"echo", "%1", `a\b`}
+	if got, want := shlex.JoinDialect(args, shlex.DialectFish), `echo '%1' 'a\\b'`; got != want {
+		t.Errorf("JoinDialect(fish) = %q, want %q", got, want)
+	}
+	if got, want := shlex.JoinDialect(args, shlex.DialectPOSIX), `echo %1 'a\b'`; got != want {
+		t.Errorf("JoinDialect(posix) = %q, want %q", got, want)
+	}
+}
+
+func TestEscapeDoubleQuoted(t *testing.T) {
+	for i, tt := range []struct {
+		in   string
+		want string
+	}{
+		{in: "plain words", want: "plain words"},
+		{in: "it's", want: "it's"},
+		{in: "$HOME `id` \"q\" \\", want: "\\$HOME \\`id\\` \\\"q\\\" \\\\"},
+		{in: "a\nb", want: "a\nb"},
+		{in: "", want: ""},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.in), func(t *testing.T) {
+			got := shlex.EscapeDoubleQuoted(tt.in)
+			if got != tt.want {
+				t.Errorf("EscapeDoubleQuoted = %q, want %q", got, tt.want)
+			}
+			line := fmt.Sprintf(`echo "<%s>"`, got)
+			if split, want := shlex.Split(line), []string{"echo", "<" + tt.in + ">"}; !reflect.DeepEqual(split, want) {
+				t.Errorf("Split(%q) = %#v, want %#v", line, split, want)
+			}
+		})
+	}
+}
+
+func TestQuoteBackquoted(t *testing.T) {
+	for i, tt := range []struct {
+		in   string
+		want string
+	}{
+		{in: "plain", want: "plain"},
+		{in: "a b", want: "'a b'"},
+		{in: "$HOME", want: "'\\$HOME'"},
+		{in: "a`b\\c", want: "'a\\`b\\\\c'"},
+		{in: "it's $x", want: "it\\\\''s \\$x'"},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.in), func(t *testing.T) {
+			got := shlex.QuoteBackquoted(tt.in)
+			if got != tt.want {
+				t.Errorf("QuoteBackquoted = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeBackquoted(t *testing.T) {
+	cmd := shlex.Join([]string{"echo", "`id`", "$HOME", `a\b`})
+	got := shlex.EscapeBackquoted(cmd)
+	if want := "echo '\\`id\\`' '\\$HOME' 'a\\\\b'"; got != want {
+		t.Errorf("EscapeBackquoted(%q) = %q, want %q", cmd, got, want)
+	}
+	if twice, want := shlex.EscapeBackquoted(got), "echo '\\\\\\`id\\\\\\`' '\\\\\\$HOME' 'a\\\\\\\\b'"; twice != want {
+		t.Errorf("EscapeBackquoted(%q) = %q, want %q", got, twice, want)
+	}
+}
+
+func TestJoinStyles(t *testing.T) {
+	args := []string{"echo", "hello world", "$HOME", "it's"}
+	styles := []shlex.Style{shlex.StyleBare, shlex.StyleDouble, shlex.StyleSingle}
+
+	got := shlex.JoinStyles(args, styles)
+	want := `echo "hello world" '$HOME' "it's"`
+	if got != want {
+		t.Errorf("JoinStyles = %q, want %q", got, want)
+	}
+	if split := shlex.Split(got); !reflect.DeepEqual(split, args) {
+		t.Errorf("Split(JoinStyles) = %#v, want %#v", split, args)
+	}
+}
+
+func TestJoinRoundTrip(t *testing.T) {
+	for i, args := range [][]string{
+		{},
+		{""},
+		{"", ""},
+		{"stuff", "more stuff"},
+		{"#comment", "a#b"},
+		{"new\nline", "tab\there"},
+		{`"double"`, `'single'`, `back\slash`},
+		{"こんにちは　世界"},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d]", i), func(t *testing.T) {
+			got := shlex.Split(shlex.Join(args))
+			if !reflect.DeepEqual(got, args) {
+				t.Errorf("Split(Join(%#v)) = %#v", args, got)
+			}
+		})
+	}
+}
+
+func TestUnquote(t *testing.T) {
+	for i, tt := range []struct {
+		in      string
+		want    string
+		wantErr error
+	}{
+		{in: "plain", want: "plain"},
+		{in: `'a b'`, want: "a b"},
+		{in: `"a \"b\" \$x \w"`, want: `a "b" $x \w`},
+		{in: `a\ b\\c`, want: `a b\c`},
+		{in: `'it'\''s'`, want: "it's"},
+		{in: `''`, want: ""},
+		{in: ``, want: ""},
+		{in: `#x`, want: "#x"},
+		{in: `a|b`, want: "a|b"},
+		{in: `a b`, wantErr: shlex.ErrNotSingleWord},
+		{in: ` a`, wantErr: shlex.ErrNotSingleWord},
+		{in: "a\n", wantErr: shlex.ErrNotSingleWord},
+		{in: `  `, wantErr: shlex.ErrNotSingleWord},
+		{in: `'a`, wantErr: shlex.ErrUnterminatedSingleQuote},
+		{in: `a\`, wantErr: shlex.ErrTrailingBackslash},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.in), func(t *testing.T) {
+			got, err := shlex.Unquote(tt.in)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Unquote = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Unquote = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	for _, s := range []string{"", "a b", "it's", `$x "y"`, "\n", "#"} {
+		if got, err := shlex.Unquote(shlex.Quote(s)); err != nil || got != s {
+			t.Errorf("Unquote(Quote(%q)) = %q, %v", s, got, err)
+		}
+	}
+}
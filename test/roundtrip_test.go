@@ -0,0 +1,51 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"testing"
+
+	"github.com/hugelgupf/go-shlex/shlextest"
+)
+
+var roundTripSeeds = []string{
+	"",
+	"\x00",
+	"echo\x00hello world",
+	"it's\x00\"quoted\"\x00back\\slash",
+	"$HOME\x00$(id)\x00`id`\x00${X:-y}\x00$''\x00$\"\"",
+	"a|b\x00&&\x00;\x00(x)\x00<in\x00>out",
+	"#comment\x00~\x00*.go\x00{a,b}",
+	"new\nline\x00\ttab\x00cr\r\x00\r\n",
+	"\\\x00'\x00\"\x00\\\n",
+	"こんにちは\x00🎉\x00\u200b",
+	"\xff\xfe",
+	"'\xff'\x00a\xc3 b\x00\xed\xa0\x80\x00\\\x80\n",
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, d := range shlextest.Dialects() {
+		for _, seed := range roundTripSeeds {
+			if err := shlextest.CheckRoundTrip(d, shlextest.FuzzArgv([]byte(seed))); err != nil {
+				t.Error(err)
+			}
+		}
+	}
+}
+
+func FuzzRoundTrip(f *testing.F) {
+	for _, seed := range roundTripSeeds {
+		f.Add([]byte(seed))
+	}
+	dialects := shlextest.Dialects()
+	f.Fuzz(func(t *testing.T, data []byte) {
+		argv := shlextest.FuzzArgv(data)
+		for _, d := range dialects {
+			if err := shlextest.CheckRoundTrip(d, argv); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+}
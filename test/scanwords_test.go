@@ -0,0 +1,57 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"bufio"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestScanShellWords(t *testing.T) {
+	for i, tt := range []struct {
+		desc string
+		in   string
+	}{
+		{desc: "empty", in: ""},
+		{desc: "blanks", in: " \n\t "},
+		{desc: "words", in: "a bb  ccc\n"},
+		{desc: "quotes", in: `echo 'a b' "c\"d" '' e\ f`},
+		{desc: "multi-line quote", in: "echo 'a\nb'\nnext"},
+		{desc: "comments", in: "a # b 'c\n# d\ne #"},
+		{desc: "unterminated", in: `a "b c`},
+		{desc: "trailing backslash", in: `a b\`},
+		{desc: "unicode", in: "こんにちは　世界 🎉"},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			want := shlex.Split(tt.in)
+			for _, r := range []struct {
+				name string
+				r    func() *bufio.Scanner
+			}{
+				{"whole", func() *bufio.Scanner { return bufio.NewScanner(strings.NewReader(tt.in)) }},
+				{"one byte", func() *bufio.Scanner { return bufio.NewScanner(iotest.OneByteReader(strings.NewReader(tt.in))) }},
+			} {
+				s := r.r()
+				s.Split(shlex.ScanShellWords)
+				got := []string{}
+				for s.Scan() {
+					got = append(got, s.Text())
+				}
+				if err := s.Err(); err != nil {
+					t.Errorf("%s: Err = %v", r.name, err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("%s: words = %#v, want %#v", r.name, got, want)
+				}
+			}
+		})
+	}
+}
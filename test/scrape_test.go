@@ -0,0 +1,100 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestSplitBestEffort(t *testing.T) {
+	for i, tt := range []struct {
+		desc          string
+		in            string
+		want          []string
+		wantConfident bool
+	}{
+		{
+			desc:          "clean",
+			in:            `run --name 'a b'`,
+			want:          []string{"run", "--name", "a b"},
+			wantConfident: true,
+		},
+		{
+			desc:          "colors",
+			in:            "\x1b[1;32mrun\x1b[0m \x1b[33m'a b'\x1b[m",
+			want:          []string{"run", "a b"},
+			wantConfident: true,
+		},
+		{
+			desc:          "hyperlink",
+			in:            "ls \x1b]8;;file:///tmp\x1b\\/tmp\x1b]8;;\a",
+			want:          []string{"ls", "/tmp"},
+			wantConfident: true,
+		},
+		{
+			desc: "stray apostrophe",
+			in:   `echo don't stop`,
+			want: []string{"echo", "don't", "stop"},
+		},
+		{
+			desc: "stray quotes",
+			in:   `echo "a 'b c`,
+			want: []string{"echo", `"a`, `'b`, "c"},
+		},
+		{
+			desc: "stray quote within quotes",
+			in:   `echo it's "a b`,
+			want: []string{"echo", "it's", `"a`, "b"},
+		},
+		{
+			desc: "truncated",
+			in:   "make -j8 CFLAGS=\"-O2 \\",
+			want: []string{"make", "-j8", `CFLAGS="-O2`},
+		},
+		{
+			desc: "trailing backslash",
+			in:   "cp a b \\",
+			want: []string{"cp", "a", "b"},
+		},
+		{
+			desc:          "incomplete escape sequence",
+			in:            "ls\x1b[3",
+			want:          []string{"ls"},
+			wantConfident: true,
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			got, confident := shlex.SplitBestEffort(tt.in)
+			if !reflect.DeepEqual(got, tt.want) || confident != tt.wantConfident {
+				t.Errorf("SplitBestEffort = %#v, %t, want %#v, %t", got, confident, tt.want, tt.wantConfident)
+			}
+		})
+	}
+}
+
+func TestStripANSI(t *testing.T) {
+	for i, tt := range []struct {
+		in   string
+		want string
+	}{
+		{"plain", "plain"},
+		{"\x1b[31mred\x1b[0m", "red"},
+		{"\x1b]0;title\abody", "body"},
+		{"\x1b]0;title\x1b\\body", "body"},
+		{"a\x1bcb", "ab"},
+		{"a\x1b", "a"},
+		{"a\x1b]unterminated", "a"},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %q", i, tt.in), func(t *testing.T) {
+			if got := shlex.StripANSI(tt.in); got != tt.want {
+				t.Errorf("StripANSI = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestEmbedScript(t *testing.T) {
+	for i, tt := range []struct {
+		desc    string
+		script  string
+		want    string
+		wantErr error
+	}{
+		{
+			desc:   "simple",
+			script: "echo $HOME",
+			want:   "sh -c 'echo $HOME'",
+		},
+		{
+			desc:   "single quotes",
+			script: "echo 'hi'",
+			want:   `sh -c 'echo '\''hi'\'''`,
+		},
+		{
+			desc:   "multi-line",
+			script: "set -e\ncd /tmp && ls\n",
+			want:   "sh -c 'set -e\ncd /tmp && ls\n'",
+		},
+		{
+			desc:    "NUL",
+			script:  "printf 'a\x00'",
+			wantErr: shlex.ErrNUL,
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			got, err := shlex.EmbedScript(tt.script)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("EmbedScript = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("EmbedScript = %q, want %q", got, tt.want)
+			}
+			if err != nil {
+				return
+			}
+			if argv, want := shlex.Split(got), []string{"sh", "-c", tt.script}; !reflect.DeepEqual(argv, want) {
+				t.Errorf("Split(EmbedScript) = %#v, want %#v", argv, want)
+			}
+		})
+	}
+}
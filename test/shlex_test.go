@@ -5,8 +5,10 @@
 package shlex_test
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
 	anmitsu "github.com/anmitsu/go-shlex"
@@ -100,6 +102,44 @@ func TestArgv(t *testing.T) {
 			anmitsuWrong: true,
 		},
 
+		// Bash treats $'...' as ANSI-C quoting and $"..." as a string
+		// to translate, so the degenerate $'' and $"" are empty.
+		{
+			desc:         "empty ANSI-C string",
+			in:           `stuff $''`,
+			want:         []string{"stuff", ""},
+			anmitsuWrong: true,
+			googWrong:    true,
+		},
+		{
+			desc:         "empty locale string",
+			in:           `stuff $""`,
+			want:         []string{"stuff", ""},
+			anmitsuWrong: true,
+			googWrong:    true,
+		},
+		{
+			desc:         "empty dollar strings within words",
+			in:           `a$''b $""c d$"" $''$""`,
+			want:         []string{"ab", "c", "d", ""},
+			anmitsuWrong: true,
+			googWrong:    true,
+		},
+		{
+			desc:         "locale string",
+			in:           `stuff $"more stuff"`,
+			want:         []string{"stuff", "more stuff"},
+			anmitsuWrong: true,
+			googWrong:    true,
+		},
+		{
+			desc:         "quoted dollar before quotes",
+			in:           `\$'' "$''" '$'"" $$'' $$$''`,
+			want:         []string{"$", "$''", "$", "$$", "$$"},
+			anmitsuWrong: true,
+			googWrong:    true,
+		},
+
 		// (Fixed) tests from anmitsu/go-shlex
 		{
 			in: `This string has an embedded apostrophe, doesn't it?`,
@@ -239,3 +279,479 @@ func TestArgv(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitDangling(t *testing.T) {
+	for i, tt := range []struct {
+		in   string
+		want []string
+	}{
+		// A trailing backslash is dropped, and an open quote with
+		// nothing after it makes no word.
+		{in: "a \\", want: []string{"a"}},
+		{in: "a '", want: []string{"a"}},
+		{in: `a "`, want: []string{"a"}},
+		{in: `a "\`, want: []string{"a"}},
+		{in: "\\", want: []string{}},
+		// Closed quotes are empty words even so.
+		{in: "a '' \\", want: []string{"a", ""}},
+		{in: `a ""'`, want: []string{"a", ""}},
+		{in: "a 'b", want: []string{"a", "b"}},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %q", i, tt.in), func(t *testing.T) {
+			if got := shlex.Split(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitStripCR(t *testing.T) {
+	for i, tt := range []struct {
+		desc  string
+		in    string
+		strip bool
+		want  []string
+	}{
+		{
+			desc: "escaped CR kept",
+			in:   "stuff \\\r\n",
+			want: []string{"stuff", "\r"},
+		},
+		{
+			desc:  "escaped CR stripped",
+			in:    "stuff \\\r\nmore\r\n",
+			strip: true,
+			want:  []string{"stuff", "\nmore"},
+		},
+		{
+			desc:  "quoted CR at end of input",
+			in:    "stuff 'more stuff\r",
+			strip: true,
+			want:  []string{"stuff", "more stuff"},
+		},
+		{
+			desc:  "multi-line quote",
+			in:    "stuff 'more\r\nstuff'\r\n",
+			strip: true,
+			want:  []string{"stuff", "more\nstuff"},
+		},
+		{
+			desc:  "lone CR kept",
+			in:    "'a\rb'",
+			strip: true,
+			want:  []string{"a\rb"},
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			got := shlex.Split(tt.in, shlex.WithStripCR(tt.strip))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitQuotedContinuation(t *testing.T) {
+	for i, tt := range []struct {
+		desc string
+		in   string
+		mode shlex.Continuation
+		want []string
+	}{
+		{
+			desc: "keep newline",
+			in:   "\"a\\\nb\" c\\\nd",
+			mode: shlex.ContinuationKeepNewline,
+			want: []string{"a\nb", "c\nd"},
+		},
+		{
+			desc: "remove",
+			in:   "\"a\\\nb\" c\\\nd",
+			mode: shlex.ContinuationRemove,
+			want: []string{"ab", "c\nd"},
+		},
+		{
+			desc: "literal",
+			in:   "\"a\\\nb\" c\\\nd",
+			mode: shlex.ContinuationLiteral,
+			want: []string{"a\\\nb", "c\nd"},
+		},
+		{
+			desc: "single quotes unaffected",
+			in:   "'a\\\nb'",
+			mode: shlex.ContinuationRemove,
+			want: []string{"a\\\nb"},
+		},
+		{
+			desc: "only continuation",
+			in:   "\"\\\n\"",
+			mode: shlex.ContinuationRemove,
+			want: []string{""},
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			got := shlex.Split(tt.in, shlex.WithQuotedContinuation(tt.mode))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitRawDoubleQuotes(t *testing.T) {
+	for i, tt := range []struct {
+		desc string
+		in   string
+		raw  bool
+		want []string
+	}{
+		{
+			desc: "escapes",
+			in:   `"a\"b" "C:\d" x\ y`,
+			want: []string{`a"b`, `C:\d`, "x y"},
+		},
+		{
+			desc: "raw",
+			in:   `"C:\dir\" "a\b" x\ y`,
+			raw:  true,
+			want: []string{`C:\dir\`, `a\b`, "x y"},
+		},
+		{
+			desc: "raw backslash-newline",
+			in:   "\"a\\\nb\"",
+			raw:  true,
+			want: []string{"a\\\nb"},
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			got := shlex.Split(tt.in, shlex.WithRawDoubleQuotes(tt.raw))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitTerminators(t *testing.T) {
+	for i, tt := range []struct {
+		desc  string
+		in    string
+		terms string
+		opts  []shlex.Option
+		want  []string
+	}{
+		{
+			desc:  "key value list",
+			in:    `a='b c',d "x,y"=z\,w`,
+			terms: ",=",
+			want:  []string{"a", "=", "b c", ",", "d", "x,y", "=", "z,w"},
+		},
+		{
+			desc:  "adjacent",
+			in:    ",,a, ,",
+			terms: ",",
+			want:  []string{",", ",", "a", ",", ","},
+		},
+		{
+			desc:  "with operators",
+			in:    "a|b:c||d",
+			terms: ":",
+			opts:  []shlex.Option{shlex.WithOperators(true)},
+			want:  []string{"a", "|", "b", ":", "c", "||", "d"},
+		},
+		{
+			desc:  "terminator is not an operator",
+			in:    "a||b",
+			terms: "|",
+			want:  []string{"a", "|", "|", "b"},
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			got := shlex.Split(tt.in, append(tt.opts, shlex.WithTerminators(tt.terms))...)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitErr(t *testing.T) {
+	for i, tt := range []struct {
+		desc    string
+		in      string
+		want    []string
+		wantErr error
+		wantPos shlex.Position
+	}{
+		{
+			desc: "valid",
+			in:   `a 'b c' "" d\ e`,
+			want: []string{"a", "b c", "", "d e"},
+		},
+		{
+			desc: "empty",
+			in:   "  ",
+			want: []string{},
+		},
+		{
+			desc:    "single quote",
+			in:      "a 'b\nc",
+			wantErr: shlex.ErrUnterminatedSingleQuote,
+			wantPos: shlex.Position{Offset: 2, Line: 1, Column: 3},
+		},
+		{
+			desc:    "double quote",
+			in:      `a "b \"`,
+			wantErr: shlex.ErrUnterminatedDoubleQuote,
+			wantPos: shlex.Position{Offset: 2, Line: 1, Column: 3},
+		},
+		{
+			desc:    "trailing backslash",
+			in:      "a\nb \\",
+			wantErr: shlex.ErrTrailingBackslash,
+			wantPos: shlex.Position{Offset: 4, Line: 2, Column: 3},
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			got, err := shlex.SplitErr(tt.in)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("SplitErr = %v", err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("SplitErr = %#v, want %#v", got, tt.want)
+				}
+				return
+			}
+			var pe *shlex.ParseError
+			if !errors.As(err, &pe) || !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SplitErr = %v, want *ParseError wrapping %v", err, tt.wantErr)
+			}
+			if pe.Pos != tt.wantPos {
+				t.Errorf("SplitErr error at %#v, want %#v", pe.Pos, tt.wantPos)
+			}
+			if got != nil {
+				t.Errorf("SplitErr = %#v, want nil", got)
+			}
+		})
+	}
+}
+
+func TestSplitN(t *testing.T) {
+	for i, tt := range []struct {
+		in   string
+		n    int
+		opts []shlex.Option
+		want []string
+	}{
+		{in: `alias ll='ls -l' # long`, n: 2, want: []string{"alias", `ll='ls -l' # long`}},
+		{in: `  a "b c"  d  `, n: 1, want: []string{`a "b c"  d  `}},
+		{in: `  a "b c"  d  `, n: 2, want: []string{"a", `"b c"  d  `}},
+		{in: `  a "b c"  d  `, n: 3, want: []string{"a", "b c", "d  "}},
+		{in: `  a "b c"  d  `, n: 4, want: []string{"a", "b c", "d"}},
+		{in: `a b # c`, n: 3, want: []string{"a", "b"}},
+		{in: `a b`, n: 0, want: nil},
+		{in: `a 'b c`, n: -1, want: []string{"a", "b c"}},
+		{in: "", n: 2, want: []string{}},
+		{in: "a|b c", n: 3, opts: []shlex.Option{shlex.WithOperators(true)}, want: []string{"a", "|", "b c"}},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %d %s", i, tt.n, tt.in), func(t *testing.T) {
+			if got := shlex.SplitN(tt.in, tt.n, tt.opts...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitN = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitApostrophes(t *testing.T) {
+	for i, tt := range []struct {
+		in   string
+		want []string
+	}{
+		{
+			in:   `This string has an embedded apostrophe, doesn't it?`,
+			want: []string{"This", "string", "has", "an", "embedded", "apostrophe,", "doesn't", "it?"},
+		},
+		{
+			in:   `l'été, c'est 'la vie' 'n'`,
+			want: []string{"l'été,", "c'est", "la vie", "n"},
+		},
+		{
+			in:   `it's "don't" 'can'\''t' a'b c'`,
+			want: []string{"it's", "don't", "can't", "a'b", "c"},
+		},
+		{
+			in:   `x'y 1'2 3'`,
+			want: []string{"x'y", "12 3"},
+		},
+		{
+			in:   `"a"'b c'`,
+			want: []string{"ab c"},
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.in), func(t *testing.T) {
+			got := shlex.Split(tt.in, shlex.WithApostrophes(true))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitComments(t *testing.T) {
+	for i, tt := range []struct {
+		in   string
+		mode shlex.CommentMode
+		want []string
+	}{
+		{in: "a #b c\nd", mode: shlex.CommentStrip, want: []string{"a", "d"}},
+		{in: "a #b c\nd", mode: shlex.CommentLiteral, want: []string{"a", "#b", "c", "d"}},
+		{in: "a #b  c\nd", mode: shlex.CommentKeep, want: []string{"a", "#b  c", "d"}},
+		{in: "a # 'open", mode: shlex.CommentKeep, want: []string{"a", "# 'open"}},
+		{in: "#\n#", mode: shlex.CommentKeep, want: []string{"#", "#"}},
+		{in: "a#b '#c'", mode: shlex.CommentKeep, want: []string{"a#b", "#c"}},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s %d", i, tt.in, tt.mode), func(t *testing.T) {
+			got := shlex.Split(tt.in, shlex.WithComments(tt.mode))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitIFS(t *testing.T) {
+	for i, tt := range []struct {
+		in   string
+		ifs  string
+		want []string
+	}{
+		{in: "a b:c", ifs: ":", want: []string{"a b", "c"}},
+		{in: "/bin::'/opt/my bin':", ifs: ":", want: []string{"/bin", "/opt/my bin"}},
+		{in: `a\,b,"c,d",e`, ifs: ",", want: []string{"a,b", "c,d", "e"}},
+		{in: "a, b,\tc", ifs: ", \t", want: []string{"a", "b", "c"}},
+		{in: "a b", ifs: "", want: []string{"a", "b"}},
+		{in: "x:#c\ny", ifs: ":", want: []string{"x", "y"}},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.in), func(t *testing.T) {
+			got := shlex.Split(tt.in, shlex.WithIFS(tt.ifs))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitANSICQuoting(t *testing.T) {
+	for i, tt := range []struct {
+		in   string
+		want []string
+	}{
+		{in: `$'a\tb\n' $'it\'s' $'\\ \" \? \e[0m'`, want: []string{"a\tb\n", "it's", "\\ \" ? \x1b[0m"}},
+		{in: `$'\x41\x7' $'\x' $'\xfe\xff'`, want: []string{"A\x07", `\x`, "\xfe\xff"}},
+		{in: `$'\101\0\1010' $'\777'`, want: []string{"A\x00A0", "\xff"}},
+		{in: `$'é\U0001F600\u12' $'\ud800'`, want: []string{"é😀\u0012", "�"}},
+		{in: `$'\cA\c[' $'\c'`, want: []string{"\x01\x1b", `\c`}},
+		{in: `$'\q' x$'' $$'a' "$'b'"`, want: []string{`\q`, "x", "$$a", "$'b'"}},
+		{in: `$'open\`, want: []string{`open\`}},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.in), func(t *testing.T) {
+			got := shlex.Split(tt.in, shlex.WithANSICQuoting(true))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	if got, want := shlex.Split(`$'a\tb'`), []string{`$a\tb`}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Split without WithANSICQuoting = %#v, want %#v", got, want)
+	}
+	if _, err := shlex.SplitErr(`$'a\`, shlex.WithANSICQuoting(true)); !errors.Is(err, shlex.ErrUnterminatedSingleQuote) {
+		t.Errorf("SplitErr = %v, want %v", err, shlex.ErrUnterminatedSingleQuote)
+	}
+}
+
+func TestAppendSplit(t *testing.T) {
+	dst := make([]string, 0, 8)
+	for i, tt := range []struct {
+		in   string
+		opts []shlex.Option
+		want []string
+	}{
+		{in: `a 'b c'`, want: []string{"a", "b c"}},
+		{in: "", want: []string{}},
+		{in: `x "y`, want: []string{"x", "y"}},
+		{in: "a|b", opts: []shlex.Option{shlex.WithOperators(true)}, want: []string{"a", "|", "b"}},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.in), func(t *testing.T) {
+			got := shlex.AppendSplit(dst[:0], tt.in, tt.opts...)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AppendSplit = %#v, want %#v", got, tt.want)
+			}
+			if &got[:1][0] != &dst[:1][0] {
+				t.Errorf("AppendSplit did not reuse dst")
+			}
+		})
+	}
+
+	got := shlex.AppendSplit([]string{"prefix"}, "a b")
+	if want := []string{"prefix", "a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AppendSplit = %#v, want %#v", got, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for i, tt := range []struct {
+		in      string
+		opts    []shlex.Option
+		wantErr error
+	}{
+		{in: ""},
+		{in: `make -C "dir" 'a b' c\ d # it's fine`},
+		{in: "a\n'b\nc'"},
+		{in: `echo "oops`, wantErr: shlex.ErrUnterminatedDoubleQuote},
+		{in: `echo 'oops`, wantErr: shlex.ErrUnterminatedSingleQuote},
+		{in: `echo oops\`, wantErr: shlex.ErrTrailingBackslash},
+		{in: `echo "oops`, opts: []shlex.Option{shlex.WithStrictErrors(false)}},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.in), func(t *testing.T) {
+			err := shlex.Validate(tt.in, tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate = %v, want %v", err, tt.wantErr)
+			}
+			if _, splitErr := shlex.SplitErr(tt.in, tt.opts...); (err == nil) != (splitErr == nil) {
+				t.Errorf("Validate = %v, but SplitErr = %v", err, splitErr)
+			}
+		})
+	}
+
+	var pe *shlex.ParseError
+	if err := shlex.Validate("ok\n  \"no"); !errors.As(err, &pe) || pe.Pos.Line != 2 || pe.Pos.Column != 3 {
+		t.Errorf("Validate = %v, want *ParseError at 2:3", err)
+	}
+}
+
+func TestValidateAllocs(t *testing.T) {
+	short := testing.AllocsPerRun(100, func() { shlex.Validate(strings.Repeat(`word "quoted words" `, 2)) })
+	long := testing.AllocsPerRun(100, func() { shlex.Validate(strings.Repeat(`word "quoted words" `, 100)) })
+	if long > short {
+		t.Errorf("Validate: %v allocations for 200 words, want at most %v as for 4", long, short)
+	}
+}
+
+func TestMustSplit(t *testing.T) {
+	if got, want := shlex.MustSplit(`go test -run 'Test(A|B)'`), []string{"go", "test", "-run", "Test(A|B)"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MustSplit = %#v, want %#v", got, want)
+	}
+	if got := shlex.MustSplit(""); got == nil || len(got) != 0 {
+		t.Errorf("MustSplit(\"\") = %#v, want empty", got)
+	}
+
+	defer func() {
+		r := recover()
+		if want := `shlex: MustSplit("echo 'x"): 1:6: unterminated single quote`; r != want {
+			t.Errorf("MustSplit panicked with %v, want %q", r, want)
+		}
+	}()
+	shlex.MustSplit(`echo 'x`)
+}
@@ -0,0 +1,40 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestStats(t *testing.T) {
+	for i, tt := range []struct {
+		in   string
+		want shlex.LineStats
+	}{
+		{in: ""},
+		{in: "# comment only"},
+		{
+			in:   "ls -la /tmp",
+			want: shlex.LineStats{Words: 3, MaxWordLen: 4},
+		},
+		{
+			in:   `echo "$HOME" '' a\ b|wc -l&&echo ${X:-$(id)}`,
+			want: shlex.LineStats{Words: 8, QuotedWords: 3, Expansions: 3, Operators: 2, MaxWordLen: 11},
+		},
+		{
+			in:   "echo 'unterminated",
+			want: shlex.LineStats{Words: 2, QuotedWords: 1, MaxWordLen: 12, Unterminated: true},
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %q", i, tt.in), func(t *testing.T) {
+			if got := shlex.Stats(tt.in); got != tt.want {
+				t.Errorf("Stats = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,454 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestLex(t *testing.T) {
+	got, err := shlex.Lex("echo  \"$HOME\"/'a b' \\$x # c\nnext")
+	if err != nil {
+		t.Fatalf("Lex = %v", err)
+	}
+	want := []shlex.Token{
+		{Value: "echo", Raw: "echo", Pos: shlex.Position{Offset: 0, Line: 1, Column: 1}},
+		{Value: "$HOME/a b", Raw: `"$HOME"/'a b'`, Pos: shlex.Position{Offset: 6, Line: 1, Column: 7}},
+		{Value: "$x", Raw: `\$x`, Pos: shlex.Position{Offset: 20, Line: 1, Column: 21}},
+		{Value: "next", Raw: "next", Pos: shlex.Position{Offset: 28, Line: 2, Column: 1}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Lex = %#v, want %#v", got, want)
+	}
+
+	got, err = shlex.Lex("a 'b")
+	if !errors.Is(err, shlex.ErrUnterminatedSingleQuote) {
+		t.Errorf("Lex = %v, want %v", err, shlex.ErrUnterminatedSingleQuote)
+	}
+	if len(got) != 2 || got[1].Raw != "'b" {
+		t.Errorf("Lex = %#v, want 2 tokens", got)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	in := `FOO=1 git "commit" -m 'a b'|wc`
+	var got []shlex.Token
+	err := shlex.Walk(in, func(tok shlex.Token) bool {
+		got = append(got, tok)
+		return true
+	}, shlex.WithOperators(true))
+	if err != nil {
+		t.Fatalf("Walk = %v", err)
+	}
+	want, _ := shlex.Lex(in, shlex.WithOperators(true))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk = %#v, want %#v", got, want)
+	}
+
+	// Stopping early skips the rest, errors included.
+	var first []string
+	err = shlex.Walk(`cmd arg 'oops`, func(tok shlex.Token) bool {
+		first = append(first, tok.Value)
+		return len(first) < 2
+	})
+	if err != nil || !reflect.DeepEqual(first, []string{"cmd", "arg"}) {
+		t.Errorf("Walk = %q, %v, want [cmd arg], nil", first, err)
+	}
+
+	var all []string
+	err = shlex.Walk(`cmd 'oops`, func(tok shlex.Token) bool {
+		all = append(all, tok.Value)
+		return true
+	})
+	if !errors.Is(err, shlex.ErrUnterminatedSingleQuote) || !reflect.DeepEqual(all, []string{"cmd", "oops"}) {
+		t.Errorf("Walk = %q, %v, want [cmd oops], %v", all, err, shlex.ErrUnterminatedSingleQuote)
+	}
+}
+
+func TestTokenOffsets(t *testing.T) {
+	in := "echo  \"a b\"\n  x\\ y|z 'é'"
+	tokens, err := shlex.Lex(in, shlex.WithOperators(true))
+	if err != nil {
+		t.Fatalf("Lex = %v", err)
+	}
+	type span struct {
+		start, end int
+	}
+	var got []span
+	for _, tok := range tokens {
+		got = append(got, span{tok.Start(), tok.End()})
+		if raw := in[tok.Start():tok.End()]; raw != tok.Raw {
+			t.Errorf("input[%d:%d] = %q, want %q", tok.Start(), tok.End(), raw, tok.Raw)
+		}
+	}
+	want := []span{{0, 4}, {6, 11}, {14, 18}, {18, 19}, {19, 20}, {21, 25}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("offsets = %v, want %v", got, want)
+	}
+}
+
+func TestLexOperators(t *testing.T) {
+	got, err := shlex.Lex(`a|b&&c>>'d;'e;(f) 2>&1 ";"`, shlex.WithOperators(true))
+	if err != nil {
+		t.Fatalf("Lex = %v", err)
+	}
+	var raw []string
+	var ops []bool
+	for _, tok := range got {
+		raw = append(raw, tok.Raw)
+		ops = append(ops, tok.Operator)
+	}
+	wantRaw := []string{"a", "|", "b", "&&", "c", ">>", "'d;'e", ";", "(", "f", ")", "2", ">&", "1", `";"`}
+	wantOps := []bool{false, true, false, true, false, true, false, true, true, false, true, false, true, false, false}
+	if !reflect.DeepEqual(raw, wantRaw) || !reflect.DeepEqual(ops, wantOps) {
+		t.Errorf("Lex = %q %v, want %q %v", raw, ops, wantRaw, wantOps)
+	}
+	if got[3].Pos.Offset != 3 || got[4].Pos.Offset != 5 {
+		t.Errorf("Lex positions = %v, %v, want offsets 3, 5", got[3].Pos, got[4].Pos)
+	}
+}
+
+func TestLexKinds(t *testing.T) {
+	const (
+		w = shlex.TokenWord
+		a = shlex.TokenAssignment
+		o = shlex.TokenOperator
+		r = shlex.TokenRedirect
+	)
+	for i, tt := range []struct {
+		in   string
+		opts []shlex.Option
+		want []shlex.TokenKind
+	}{
+		{in: "ls -l", want: []shlex.TokenKind{w, w}},
+		{in: "A=1 B='x y' make C=2", want: []shlex.TokenKind{a, a, w, w}},
+		{in: "A=1 B=2", want: []shlex.TokenKind{a, a}},
+		{in: `"A"=1 A\=1 =1 1A=2`, want: []shlex.TokenKind{w, w, w, w}},
+		// Without WithOperators, there is only one command.
+		{in: "A=1 a; B=2 b", want: []shlex.TokenKind{a, w, w, w}},
+		{
+			in:   "A=1 a >out B=2|B=3 b 2>&1&&(C=4 c)",
+			opts: []shlex.Option{shlex.WithOperators(true)},
+			want: []shlex.TokenKind{a, w, r, w, w, o, a, w, w, r, w, o, o, a, w, o},
+		},
+		// A redirection before the command does not end the assignments.
+		{
+			in:   "<in A=1 a",
+			opts: []shlex.Option{shlex.WithOperators(true)},
+			want: []shlex.TokenKind{r, w, a, w},
+		},
+		{
+			in:   "A=1 # c\nB=2 b",
+			opts: []shlex.Option{shlex.WithComments(shlex.CommentKeep)},
+			want: []shlex.TokenKind{a, shlex.TokenComment, a, w},
+		},
+		{
+			in:   "a;A=1 b",
+			opts: []shlex.Option{shlex.WithTerminators(";")},
+			want: []shlex.TokenKind{w, o, a, w},
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.in), func(t *testing.T) {
+			tokens, err := shlex.Lex(tt.in, tt.opts...)
+			if err != nil {
+				t.Fatalf("Lex = %v", err)
+			}
+			var got []shlex.TokenKind
+			for _, tok := range tokens {
+				got = append(got, tok.Kind)
+				if tok.Operator != (tok.Kind == o || tok.Kind == r) {
+					t.Errorf("%q: Operator = %v with Kind %v", tok.Raw, tok.Operator, tok.Kind)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("kinds = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenKindString(t *testing.T) {
+	for _, tt := range []struct {
+		k    shlex.TokenKind
+		want string
+	}{
+		{shlex.TokenWord, "word"},
+		{shlex.TokenAssignment, "assignment"},
+		{shlex.TokenOperator, "operator"},
+		{shlex.TokenRedirect, "redirect"},
+		{shlex.TokenComment, "comment"},
+		{shlex.TokenKind(200), "TokenKind(200)"},
+	} {
+		if got := tt.k.String(); got != tt.want {
+			t.Errorf("%d.String() = %q, want %q", uint8(tt.k), got, tt.want)
+		}
+	}
+}
+
+func TestLexAnnotations(t *testing.T) {
+	for i, tt := range []struct {
+		in   string
+		want []string
+	}{
+		{in: "echo ok"},
+		{in: "echo 'it is", want: []string{"auto-closed single quote at offset 5"}},
+		{in: `echo ok "a 'b`, want: []string{"auto-closed double quote at offset 8"}},
+		{in: `echo 'a'"b`, want: []string{"auto-closed double quote at offset 8"}},
+		{in: `echo a\`, want: []string{"dropped trailing backslash at offset 6"}},
+		{in: "# 'comment"},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.in), func(t *testing.T) {
+			tokens, err := shlex.Lex(tt.in, shlex.WithStrictErrors(false))
+			if err != nil {
+				t.Fatalf("Lex = %v", err)
+			}
+			var got []string
+			for j, tok := range tokens {
+				if j < len(tokens)-1 && len(tok.Annotations) > 0 {
+					t.Errorf("token %q: Annotations = %v, want none", tok.Raw, tok.Annotations)
+				}
+				for _, a := range tok.Annotations {
+					got = append(got, a.String())
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Annotations = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	tokens, err := shlex.Lex("a 'b", shlex.WithStrictErrors(false), shlex.WithSource("f"))
+	if err != nil {
+		t.Fatalf("Lex = %v", err)
+	}
+	want := []shlex.Annotation{{Pos: shlex.Position{Source: "f", Offset: 2, Line: 1, Column: 3}, Err: shlex.ErrUnterminatedSingleQuote, Suffix: "'"}}
+	if got := tokens[1].Annotations; !reflect.DeepEqual(got, want) {
+		t.Errorf("Annotations = %+v, want %+v", got, want)
+	}
+
+	for i, tt := range []struct {
+		in   string
+		want string
+	}{
+		{in: "echo 'it is", want: "'"},
+		{in: `echo "it is`, want: `"`},
+		{in: `echo "it is\`, want: `\"`},
+		{in: `echo a\`, want: `\`},
+		{in: `echo "a'b`, want: `"`},
+	} {
+		t.Run(fmt.Sprintf("Suffix [%02d] %s", i, tt.in), func(t *testing.T) {
+			tokens, _ := shlex.Lex(tt.in, shlex.WithStrictErrors(false))
+			a := tokens[len(tokens)-1].Annotations
+			if len(a) != 1 || a[0].Suffix != tt.want {
+				t.Fatalf("Annotations = %+v, want Suffix %q", a, tt.want)
+			}
+			if _, err := shlex.SplitErr(tt.in + a[0].Suffix); err != nil {
+				t.Errorf("SplitErr(%q) = %v, want nil", tt.in+a[0].Suffix, err)
+			}
+		})
+	}
+
+	// Strict lexing reports the error instead.
+	tokens, _ = shlex.Lex("a 'b")
+	if got := tokens[1].Annotations; got != nil {
+		t.Errorf("strict Annotations = %v, want none", got)
+	}
+}
+
+func TestSpans(t *testing.T) {
+	for i, tt := range []struct {
+		in      string
+		want    []string
+		wantErr error
+	}{
+		{in: "", want: []string{""}},
+		{in: "ls", want: []string{"", "ls", ""}},
+		{in: "  a  'b c'\t# note\n", want: []string{"  ", "a", "  ", "'b c'", "\t# note\n"}},
+		{in: "cat f|grep x>out;", want: []string{"", "cat", " ", "f", "", "|", "", "grep", " ", "x", "", ">", "", "out", "", ";", ""}},
+		{in: "echo 'oops", want: []string{"", "echo", " ", "'oops", ""}, wantErr: shlex.ErrUnterminatedSingleQuote},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %q", i, tt.in), func(t *testing.T) {
+			spans, err := shlex.Spans(tt.in, shlex.WithOperators(true))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Spans = %v, want %v", err, tt.wantErr)
+			}
+			var raw []string
+			var b strings.Builder
+			for j, sp := range spans {
+				raw = append(raw, sp.Raw)
+				if sp.Token != (j%2 == 1) {
+					t.Errorf("span %d: Token = %v", j, sp.Token)
+				}
+				if sp.Offset != b.Len() {
+					t.Errorf("span %d: Offset = %d, want %d", j, sp.Offset, b.Len())
+				}
+				b.WriteString(sp.Raw)
+			}
+			if !reflect.DeepEqual(raw, tt.want) {
+				t.Errorf("Spans = %q, want %q", raw, tt.want)
+			}
+			if b.String() != tt.in {
+				t.Errorf("concatenated Spans = %q, want %q", b.String(), tt.in)
+			}
+		})
+	}
+}
+
+func TestSpansValue(t *testing.T) {
+	in := "cp\t'my file'  \\\n\"b\"\\ c # x"
+	spans, err := shlex.Spans(in)
+	if err != nil {
+		t.Fatalf("Spans = %v", err)
+	}
+	var values []string
+	var b strings.Builder
+	for _, sp := range spans {
+		if sp.Token {
+			values = append(values, sp.Value)
+			// A targeted edit leaves everything else byte for byte.
+			if sp.Value == "my file" {
+				b.WriteString(shlex.Quote("your file"))
+				continue
+			}
+		} else if len(sp.Value) > 0 {
+			t.Errorf("separator %q: Value = %q, want empty", sp.Raw, sp.Value)
+		}
+		b.WriteString(sp.Raw)
+	}
+	if want := shlex.Split(in); !reflect.DeepEqual(values, want) {
+		t.Errorf("Span values = %q, want %q", values, want)
+	}
+	if want := "cp\t'your file'  \\\n\"b\"\\ c # x"; b.String() != want {
+		t.Errorf("edited = %q, want %q", b.String(), want)
+	}
+}
+
+func TestTokenSegments(t *testing.T) {
+	type seg struct {
+		kind shlex.SegmentKind
+		raw  string
+	}
+	for i, tt := range []struct {
+		in   string
+		want []seg
+	}{
+		{in: "file", want: []seg{{shlex.SegmentLiteral, "file"}}},
+		{in: "*.go", want: []seg{{shlex.SegmentGlob, "*"}, {shlex.SegmentLiteral, ".go"}}},
+		{in: "file?[ab]", want: []seg{{shlex.SegmentLiteral, "file"}, {shlex.SegmentGlob, "?[ab]"}}},
+		{in: "[!]x]y", want: []seg{{shlex.SegmentGlob, "[!]x]"}, {shlex.SegmentLiteral, "y"}}},
+		{in: "a[b", want: []seg{{shlex.SegmentLiteral, "a[b"}}},
+		{in: `'*'.go`, want: []seg{{shlex.SegmentQuoted, `'*'`}, {shlex.SegmentLiteral, ".go"}}},
+		{in: `\*"?"'x'*`, want: []seg{{shlex.SegmentQuoted, `\*"?"'x'`}, {shlex.SegmentGlob, "*"}}},
+		{in: `"a\b\$"`, want: []seg{{shlex.SegmentQuoted, `"a\b\$"`}}},
+		{in: "'open", want: []seg{{shlex.SegmentQuoted, "'open"}}},
+		{in: `$"x"`, want: []seg{{shlex.SegmentQuoted, `$"x"`}}},
+		{in: `a$''b`, want: []seg{{shlex.SegmentLiteral, "a"}, {shlex.SegmentQuoted, `$''`}, {shlex.SegmentLiteral, "b"}}},
+		{in: `$'x'*`, want: []seg{{shlex.SegmentQuoted, `$'x'`}, {shlex.SegmentGlob, "*"}}},
+		{in: `$$'x'`, want: []seg{{shlex.SegmentLiteral, "$$"}, {shlex.SegmentQuoted, "'x'"}}},
+		{in: `\$'x'`, want: []seg{{shlex.SegmentQuoted, `\$'x'`}}},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.in), func(t *testing.T) {
+			tokens, _ := shlex.Lex(tt.in)
+			if len(tokens) != 1 {
+				t.Fatalf("Lex(%q) = %v, want 1 token", tt.in, tokens)
+			}
+			segs := tokens[0].Segments()
+			var got []seg
+			var raw, value strings.Builder
+			for _, s := range segs {
+				got = append(got, seg{s.Kind, s.Raw})
+				if s.Offset != raw.Len() {
+					t.Errorf("Segment %q: Offset = %d, want %d", s.Raw, s.Offset, raw.Len())
+				}
+				raw.WriteString(s.Raw)
+				value.WriteString(s.Value)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Segments = %v, want %v", got, tt.want)
+			}
+			if raw.String() != tokens[0].Raw || value.String() != tokens[0].Value {
+				t.Errorf("Segments join to %q, %q, want %q, %q", raw.String(), value.String(), tokens[0].Raw, tokens[0].Value)
+			}
+		})
+	}
+}
+
+func TestTokenQuoteParts(t *testing.T) {
+	type part struct {
+		quoting shlex.Quoting
+		raw     string
+	}
+	for i, tt := range []struct {
+		in      string
+		want    []part
+		quoting shlex.Quoting
+	}{
+		{in: "file", want: []part{{shlex.QuotingBare, "file"}}, quoting: shlex.QuotingBare},
+		{in: `a\ b\'`, want: []part{{shlex.QuotingBare, `a\ b\'`}}, quoting: shlex.QuotingBare},
+		{in: "'a b'", want: []part{{shlex.QuotingSingle, "'a b'"}}, quoting: shlex.QuotingSingle},
+		{in: `"a \" b"`, want: []part{{shlex.QuotingDouble, `"a \" b"`}}, quoting: shlex.QuotingDouble},
+		{in: `$'a\'b'`, want: []part{{shlex.QuotingANSIC, `$'a\'b'`}}, quoting: shlex.QuotingANSIC},
+		{in: `$"x"`, want: []part{{shlex.QuotingDouble, `$"x"`}}, quoting: shlex.QuotingDouble},
+		{in: "''", want: []part{{shlex.QuotingSingle, "''"}}, quoting: shlex.QuotingSingle},
+		{
+			in:      `--opt='a b'"$c"d`,
+			want:    []part{{shlex.QuotingBare, "--opt="}, {shlex.QuotingSingle, "'a b'"}, {shlex.QuotingDouble, `"$c"`}, {shlex.QuotingBare, "d"}},
+			quoting: shlex.QuotingMixed,
+		},
+		{in: "'a''b'", want: []part{{shlex.QuotingSingle, "'a'"}, {shlex.QuotingSingle, "'b'"}}, quoting: shlex.QuotingMixed},
+		{in: `"open`, want: []part{{shlex.QuotingDouble, `"open`}}, quoting: shlex.QuotingDouble},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.in), func(t *testing.T) {
+			tokens, _ := shlex.Lex(tt.in)
+			if len(tokens) != 1 {
+				t.Fatalf("Lex(%q) = %v, want 1 token", tt.in, tokens)
+			}
+			var got []part
+			var raw strings.Builder
+			for _, p := range tokens[0].QuoteParts() {
+				got = append(got, part{p.Quoting, p.Raw})
+				if p.Offset != raw.Len() {
+					t.Errorf("QuotePart %q: Offset = %d, want %d", p.Raw, p.Offset, raw.Len())
+				}
+				raw.WriteString(p.Raw)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QuoteParts = %v, want %v", got, tt.want)
+			}
+			if raw.String() != tokens[0].Raw {
+				t.Errorf("QuoteParts join to %q, want %q", raw.String(), tokens[0].Raw)
+			}
+			if q := tokens[0].Quoting(); q != tt.quoting {
+				t.Errorf("Quoting = %v, want %v", q, tt.quoting)
+			}
+		})
+	}
+}
+
+func TestLexUTF16Positions(t *testing.T) {
+	got, err := shlex.Lex("a 😀 é\nb", shlex.WithUTF16Positions(true))
+	if err != nil {
+		t.Fatalf("Lex = %v", err)
+	}
+	want := []shlex.Position{
+		{Offset: 0, Line: 1, Column: 1, UTF16Offset: 0, UTF16Column: 1},
+		{Offset: 2, Line: 1, Column: 3, UTF16Offset: 2, UTF16Column: 3},
+		{Offset: 7, Line: 1, Column: 5, UTF16Offset: 5, UTF16Column: 6},
+		{Offset: 10, Line: 2, Column: 1, UTF16Offset: 7, UTF16Column: 1},
+	}
+	var pos []shlex.Position
+	for _, tok := range got {
+		pos = append(pos, tok.Pos)
+	}
+	if !reflect.DeepEqual(pos, want) {
+		t.Errorf("Lex positions = %+v, want %+v", pos, want)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestTokenizer(t *testing.T) {
+	for i, tt := range []struct {
+		desc     string
+		in       string
+		opts     []shlex.Option
+		want     []string
+		wantRaw  []string
+		wantRest []string
+		wantErr  error
+	}{
+		{
+			desc:     "words",
+			in:       `git commit -m 'a b'`,
+			want:     []string{"git", "commit", "-m", "a b"},
+			wantRaw:  []string{"git", "commit", "-m", "'a b'"},
+			wantRest: []string{`commit -m 'a b'`, `-m 'a b'`, `'a b'`, ""},
+			wantErr:  io.EOF,
+		},
+		{
+			desc:     "operators",
+			in:       `ls|wc`,
+			opts:     []shlex.Option{shlex.WithOperators(true)},
+			want:     []string{"ls", "|", "wc"},
+			wantRaw:  []string{"ls", "|", "wc"},
+			wantRest: []string{"|wc", "wc", ""},
+			wantErr:  io.EOF,
+		},
+		{
+			desc:     "unterminated",
+			in:       `echo "a`,
+			want:     []string{"echo", "a"},
+			wantRaw:  []string{"echo", `"a`},
+			wantRest: []string{`"a`, ""},
+			wantErr:  shlex.ErrUnterminatedDoubleQuote,
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			tok := shlex.NewTokenizer(tt.in, tt.opts...)
+			var got, gotRaw, gotRest []string
+			var err error
+			for {
+				var w string
+				w, err = tok.Next()
+				if err != nil {
+					break
+				}
+				got = append(got, w)
+				gotRaw = append(gotRaw, tok.Raw())
+				gotRest = append(gotRest, tok.Rest())
+			}
+			if w, err := tok.Next(); w != "" || !errors.Is(err, tt.wantErr) {
+				t.Errorf("Next after end = %q, %v, want %v", w, err, tt.wantErr)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Next = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Next = %#v, want %#v", got, tt.want)
+			}
+			if !reflect.DeepEqual(gotRaw, tt.wantRaw) {
+				t.Errorf("Raw = %#v, want %#v", gotRaw, tt.wantRaw)
+			}
+			if !reflect.DeepEqual(gotRest, tt.wantRest) {
+				t.Errorf("Rest = %#v, want %#v", gotRest, tt.wantRest)
+			}
+		})
+	}
+}
+
+func TestTokenizerStopEarly(t *testing.T) {
+	tok := shlex.NewTokenizer("  sudo  -u root 'unterminated")
+	name, err := tok.Next()
+	if name != "sudo" || err != nil {
+		t.Fatalf("Next = %q, %v, want sudo", name, err)
+	}
+	if p := tok.Pos(); p.Offset != 2 || p.Column != 3 {
+		t.Errorf("Pos = %v, want offset 2", p)
+	}
+	if p := tok.End(); p.Offset != 6 || p.Column != 7 {
+		t.Errorf("End = %v, want offset 6", p)
+	}
+	if rest := tok.Rest(); rest != " -u root 'unterminated" {
+		t.Errorf("Rest = %q", rest)
+	}
+}
+
+func TestTokenizerReset(t *testing.T) {
+	tok := shlex.NewTokenizer("a b c", shlex.WithOperators(true))
+	if w, _ := tok.Next(); w != "a" {
+		t.Fatalf("Next = %q, want a", w)
+	}
+	tok.Reset("x|'y z'")
+	var got []string
+	for {
+		w, err := tok.Next()
+		if err != nil {
+			break
+		}
+		got = append(got, w)
+	}
+	if want := []string{"x", "|", "y z"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Next = %#v, want %#v", got, want)
+	}
+	if tok.Raw() != "'y z'" || tok.Rest() != "" {
+		t.Errorf("Raw, Rest = %q, %q, want 'y z', empty", tok.Raw(), tok.Rest())
+	}
+}
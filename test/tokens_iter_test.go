@@ -0,0 +1,82 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.23
+
+package shlex_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestTokens(t *testing.T) {
+	for i, tt := range []struct {
+		desc    string
+		in      string
+		opts    []shlex.Option
+		want    []string
+		wantErr error
+	}{
+		{
+			desc: "words",
+			in:   `a 'b c' "d"`,
+			want: []string{"a", "b c", "d"},
+		},
+		{
+			desc: "empty",
+			in:   "  # comment",
+		},
+		{
+			desc: "operators",
+			in:   "a&&b",
+			opts: []shlex.Option{shlex.WithOperators(true)},
+			want: []string{"a", "&&", "b"},
+		},
+		{
+			desc:    "unterminated",
+			in:      `a 'b`,
+			want:    []string{"a", "b"},
+			wantErr: shlex.ErrUnterminatedSingleQuote,
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.desc), func(t *testing.T) {
+			var got []string
+			var gotErr error
+			for w, err := range shlex.Tokens(tt.in, tt.opts...) {
+				if err != nil {
+					if gotErr != nil {
+						t.Fatalf("second error %v after %v", err, gotErr)
+					}
+					gotErr = err
+					continue
+				}
+				got = append(got, w)
+			}
+			if !errors.Is(gotErr, tt.wantErr) {
+				t.Errorf("Tokens error = %v, want %v", gotErr, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokens = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokensBreak(t *testing.T) {
+	var got []string
+	for w := range shlex.Tokens("sudo ls 'unterminated") {
+		got = append(got, w)
+		if len(got) == 2 {
+			break
+		}
+	}
+	if want := []string{"sudo", "ls"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokens = %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestParseTranscript(t *testing.T) {
+	in := "Install it first:\n" +
+		"$ echo 'hello world'\n" +
+		"hello world\n" +
+		"$ ls \\\n" +
+		">   /tmp\r\n" +
+		"a\n" +
+		"\n" +
+		"b\n" +
+		"$ printf '%s\n" +
+		"> x'\n" +
+		"$\n" +
+		"$ true"
+
+	got, err := shlex.ParseTranscript(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("ParseTranscript = %v", err)
+	}
+	want := []shlex.TranscriptEntry{
+		{
+			Line:    2,
+			Command: "echo 'hello world'",
+			Args:    []string{"echo", "hello world"},
+			Output:  "hello world\n",
+		},
+		{
+			Line:    4,
+			Command: "ls \\\n  /tmp",
+			Args:    []string{"ls", "/tmp"},
+			Output:  "a\n\nb\n",
+		},
+		{
+			Line:    9,
+			Command: "printf '%s\nx'",
+			Args:    []string{"printf", "%s\nx"},
+		},
+		{
+			Line: 11,
+			Args: []string{},
+		},
+		{
+			Line:    12,
+			Command: "true",
+			Args:    []string{"true"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseTranscript =\n%#v\nwant\n%#v", got, want)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestViews(t *testing.T) {
+	for i, tt := range []struct {
+		in         string
+		wantRaw    []string
+		wantQuoted []bool
+	}{
+		{in: ""},
+		{in: "  # comment"},
+		{
+			in:         "ls -la  /tmp",
+			wantRaw:    []string{"ls", "-la", "/tmp"},
+			wantQuoted: []bool{false, false, false},
+		},
+		{
+			in:         `echo "a \"b\"" 'c d'e\ f # x` + "\nnext",
+			wantRaw:    []string{"echo", `"a \"b\""`, `'c d'e\ f`, "next"},
+			wantQuoted: []bool{false, true, true, false},
+		},
+		{
+			in:         "a#b ''　\"\" x\\",
+			wantRaw:    []string{"a#b", "''", `""`, `x\`},
+			wantQuoted: []bool{false, true, true, true},
+		},
+		{
+			in:         "echo 'open",
+			wantRaw:    []string{"echo", "'open"},
+			wantQuoted: []bool{false, true},
+		},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %q", i, tt.in), func(t *testing.T) {
+			views := shlex.Views(tt.in)
+			var raw, values []string
+			var quoted []bool
+			for j := range views {
+				v := &views[j]
+				raw = append(raw, v.Raw())
+				quoted = append(quoted, v.Quoted())
+				values = append(values, v.Value())
+				if tt.in[v.Offset():v.Offset()+len(v.Raw())] != v.Raw() {
+					t.Errorf("view %d: Offset = %d", j, v.Offset())
+				}
+			}
+			if !reflect.DeepEqual(raw, tt.wantRaw) || !reflect.DeepEqual(quoted, tt.wantQuoted) {
+				t.Errorf("Views = %q %v, want %q %v", raw, quoted, tt.wantRaw, tt.wantQuoted)
+			}
+			if want := shlex.Split(tt.in); len(want) > 0 && !reflect.DeepEqual(values, want) {
+				t.Errorf("Views values = %q, want %q", values, want)
+			}
+
+			first, ok := shlex.FirstView(tt.in)
+			if ok != (len(views) > 0) || ok && first.Raw() != views[0].Raw() {
+				t.Errorf("FirstView = %q, %v", first.Raw(), ok)
+			}
+		})
+	}
+}
+
+func TestFirstViewAllocs(t *testing.T) {
+	line := "grep -r pattern 'some dir'"
+	allocs := testing.AllocsPerRun(100, func() {
+		v, ok := shlex.FirstView(line)
+		if !ok || v.Value() != "grep" {
+			t.Fatal("FirstView failed")
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("FirstView and Value allocated %v times, want 0", allocs)
+	}
+}
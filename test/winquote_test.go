@@ -0,0 +1,51 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+func TestQuoteWindows(t *testing.T) {
+	for i, tt := range []struct {
+		in   string
+		want string
+	}{
+		{in: "", want: `""`},
+		{in: `C:\Windows\notepad.exe`, want: `C:\Windows\notepad.exe`},
+		{in: `C:\Program Files\x`, want: `"C:\Program Files\x"`},
+		{in: `a "b" c`, want: `"a \"b\" c"`},
+		{in: `with\"quote`, want: `"with\\\"quote"`},
+		{in: `trailing dir\`, want: `"trailing dir\\"`},
+		{in: `$HOME 'x'`, want: `"$HOME 'x'"`},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.in), func(t *testing.T) {
+			if got := shlex.QuoteWindows(tt.in); got != tt.want {
+				t.Errorf("QuoteWindows = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJoinFor(t *testing.T) {
+	argv := []string{"run", "a b", `c"d`}
+	for _, tt := range []struct {
+		goos string
+		want string
+	}{
+		{goos: "windows", want: `run "a b" "c\"d"`},
+		{goos: "linux", want: `run 'a b' 'c"d'`},
+		{goos: "darwin", want: `run 'a b' 'c"d'`},
+	} {
+		t.Run(tt.goos, func(t *testing.T) {
+			if got := shlex.JoinFor(tt.goos, argv); got != tt.want {
+				t.Errorf("JoinFor = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hugelgupf/go-shlex"
+)
+
+// raws returns the Raw of each token.
+func raws(tokens []shlex.Token) []string {
+	if tokens == nil {
+		return nil
+	}
+	ret := []string{}
+	for _, t := range tokens {
+		ret = append(ret, t.Raw)
+	}
+	return ret
+}
+
+func TestForWords(t *testing.T) {
+	for i, tt := range []struct {
+		in       string
+		wantName string
+		want     []string
+		wantErr  error
+	}{
+		{in: `for f in *.go "a b" $x; do echo "$f"; done`, wantName: "f", want: []string{"*.go", `"a b"`, "$x"}},
+		{in: "for i in 1 2 3\ndo\n  echo $i\ndone", wantName: "i", want: []string{"1", "2", "3"}},
+		{in: "for a in x do; do :; done", wantName: "a", want: []string{"x", "do"}},
+		{in: "for arg; do :; done", wantName: "arg"},
+		{in: "for arg\nin x; do :; done", wantName: "arg"},
+		{in: "for x in; do :; done", wantName: "x", want: []string{}},
+		{in: "cd /tmp && for d in $(ls) 'x'; do :; done", wantName: "d", want: []string{"$(ls)", "'x'"}},
+		{in: "echo for x in y", wantErr: shlex.ErrNoForLoop},
+		{in: `for "x" in y`, wantErr: shlex.ErrNoForLoop},
+		{in: "for x in 'y", wantErr: shlex.ErrUnterminatedSingleQuote},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.in), func(t *testing.T) {
+			name, words, err := shlex.ForWords(tt.in)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ForWords = %v, want %v", err, tt.wantErr)
+			}
+			if got := raws(words); name != tt.wantName || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ForWords = %q, %#v, want %q, %#v", name, got, tt.wantName, tt.want)
+			}
+		})
+	}
+}
+
+func TestCasePatterns(t *testing.T) {
+	for i, tt := range []struct {
+		in       string
+		wantWord string
+		want     [][]string
+		wantErr  error
+	}{
+		{
+			in:       "case $1 in\nstart|restart) run ;;\n'-h'|*help) usage ;;\nesac",
+			wantWord: "$1",
+			want:     [][]string{{"start", "restart"}, {"'-h'", "*help"}},
+		},
+		{
+			in:       `case "$x" in (a) echo a;; ("b c"|[0-9]*) (cd /; ls) ;; *) esac`,
+			wantWord: `"$x"`,
+			want:     [][]string{{"a"}, {`"b c"`, "[0-9]*"}, {"*"}},
+		},
+		{
+			in:       "case $a in x) case $b in y) ;; esac;; z) echo esac;; esac; echo done",
+			wantWord: "$a",
+			want:     [][]string{{"x"}, {"z"}},
+		},
+		{
+			in:       "if true; then case x in *) :\nesac; fi",
+			wantWord: "x",
+			want:     [][]string{{"*"}},
+		},
+		{in: "echo case x in", wantErr: shlex.ErrNoCase},
+		{in: "case x in a) :;;", wantWord: "x", want: [][]string{{"a"}}, wantErr: shlex.ErrUnterminatedCase},
+	} {
+		t.Run(fmt.Sprintf("Test [%02d] %s", i, tt.in), func(t *testing.T) {
+			word, clauses, err := shlex.CasePatterns(tt.in)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CasePatterns = %v, want %v", err, tt.wantErr)
+			}
+			var got [][]string
+			for _, c := range clauses {
+				got = append(got, raws(c))
+			}
+			if word.Raw != tt.wantWord || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CasePatterns = %q, %q, want %q, %q", word.Raw, got, tt.wantWord, tt.want)
+			}
+		})
+	}
+
+	_, clauses, _ := shlex.CasePatterns(`case $f in *.go|'*.c') esac`)
+	var kinds []shlex.SegmentKind
+	for _, p := range clauses[0] {
+		kinds = append(kinds, p.Segments()[0].Kind)
+	}
+	if want := []shlex.SegmentKind{shlex.SegmentGlob, shlex.SegmentQuoted}; !reflect.DeepEqual(kinds, want) {
+		t.Errorf("pattern segments = %v, want %v", kinds, want)
+	}
+}
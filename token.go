@@ -0,0 +1,536 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// TokenKind is the kind of a Token.
+type TokenKind uint8
+
+const (
+	// TokenWord is an ordinary word, such as a command name, an argument
+	// or the target of a redirection.
+	TokenWord TokenKind = iota
+
+	// TokenAssignment is a variable assignment such as FOO=bar preceding
+	// a command, as by EnvPrefix.
+	TokenAssignment
+
+	// TokenOperator is an operator split off by WithOperators that is not
+	// a redirection, such as | or &&, or a terminator of WithTerminators.
+	TokenOperator
+
+	// TokenRedirect is a redirection operator split off by WithOperators,
+	// such as > or 2>&1's >&.
+	TokenRedirect
+
+	// TokenComment is a comment kept by CommentKeep.
+	TokenComment
+)
+
+func (k TokenKind) String() string {
+	switch k {
+	case TokenWord:
+		return "word"
+	case TokenAssignment:
+		return "assignment"
+	case TokenOperator:
+		return "operator"
+	case TokenRedirect:
+		return "redirect"
+	case TokenComment:
+		return "comment"
+	}
+	return fmt.Sprintf("TokenKind(%d)", uint8(k))
+}
+
+// redirects are the operators that redirect input or output.
+var redirects = map[string]struct{}{
+	"<": {}, "<<": {}, "<<-": {}, "<<<": {}, "<>": {}, "<&": {},
+	">": {}, ">>": {}, ">&": {}, ">|": {}, "&>": {}, "&>>": {},
+}
+
+// Token is a single word of a line, as found by Lex.
+type Token struct {
+	// Value is the word with quotes and escapes removed, as returned by
+	// Split.
+	Value string
+
+	// Raw is the word exactly as written in the input, with quotes and
+	// escapes intact.
+	Raw string
+
+	// Pos is the position of the start of the word.
+	Pos Position
+
+	// Operator is set if the token is an unquoted shell operator split off
+	// by WithOperators, such as "|" or "&&", or a terminator of
+	// WithTerminators.
+	Operator bool
+
+	// Kind classifies the token. Operator is set for TokenOperator and
+	// TokenRedirect.
+	Kind TokenKind
+
+	// Annotations note what was repaired to lex the token with
+	// WithStrictErrors(false), such as a quote left open at the end of
+	// the input, so that it can be pointed out without failing.
+	Annotations []Annotation
+}
+
+// Annotation is a non-fatal problem found in a token.
+type Annotation struct {
+	// Pos is the position of the offending construct, e.g. the opening
+	// quote of an unterminated string.
+	Pos Position
+
+	// Err is the problem, e.g. ErrUnterminatedSingleQuote.
+	Err error
+
+	// Suffix is the text that, appended to the input, fixes the problem,
+	// e.g. the missing closing quote, for editors to offer as a quick fix.
+	// A backslash at the end of the input is fixed by a second one
+	// escaping it, which keeps the backslash that the repair drops.
+	Suffix string
+}
+
+// String describes the problem and the repair, e.g. "auto-closed single
+// quote at offset 17".
+func (a Annotation) String() string {
+	var what string
+	switch a.Err {
+	case ErrUnterminatedSingleQuote:
+		what = "auto-closed single quote"
+	case ErrUnterminatedDoubleQuote:
+		what = "auto-closed double quote"
+	case ErrTrailingBackslash:
+		what = "dropped trailing backslash"
+	default:
+		what = fmt.Sprint(a.Err)
+	}
+	return fmt.Sprintf("%s at offset %d", what, a.Pos.Offset)
+}
+
+// Start returns the byte offset of the start of the token in the input.
+func (t Token) Start() int {
+	return t.Pos.Offset
+}
+
+// End returns the byte offset just past the end of the token in the input, so
+// that input[t.Start():t.End()] is t.Raw.
+func (t Token) End() int {
+	return t.Pos.Offset + len(t.Raw)
+}
+
+// Lex is the first phase of a two-phase alternative to Split: it splits s
+// into tokens, keeping the raw text of each word, without expanding anything.
+// The tokens can be inspected or rewritten, and then expanded by an
+// Expander.
+//
+// If s ends within quotes or with a trailing backslash, Lex returns all
+// tokens along with a *ParseError. With WithStrictErrors(false), it returns
+// no error, and the last token has an Annotation instead.
+func Lex(s string, opts ...Option) ([]Token, error) {
+	var tokens []Token
+	err := Walk(s, func(t Token) bool {
+		tokens = append(tokens, t)
+		return true
+	}, opts...)
+	return tokens, err
+}
+
+// Walk calls fn with each token of s, as found by Lex, until fn returns
+// false, so that callers needing only the first few tokens neither scan the
+// rest of s nor build a slice. Errors are reported as by Lex, after fn has
+// been called with the incomplete final token; Walk returns nil if fn stops
+// it.
+func Walk(s string, fn func(t Token) bool, opts ...Option) error {
+	l := &Lexer{cfg: newConfig(opts)}
+	l.sc = newScanner(strings.NewReader(s), &l.cfg)
+
+	// prefix is set while assignments may precede the command, and target
+	// if the next word is the target of a redirection.
+	prefix, target := true, false
+	for {
+		res := l.read()
+		if res.err == io.EOF {
+			return nil
+		}
+		if res.err != nil {
+			return res.err
+		}
+		t := newToken(s, res.word)
+		if l.cfg.lenient && res.word.unterminated != nil {
+			open := res.word.open
+			open.Source = l.cfg.source
+			t.Annotations = []Annotation{{Pos: open, Err: res.word.unterminated, Suffix: res.word.suffix}}
+		}
+		_, redirect := redirects[t.Raw]
+		switch {
+		case res.word.comment:
+			t.Kind = TokenComment
+		case t.Operator && redirect && l.cfg.operators:
+			t.Kind = TokenRedirect
+			target = true
+		case t.Operator:
+			t.Kind = TokenOperator
+			prefix = startsCommand(t)
+			target = false
+		case target:
+			target = false
+		case prefix && res.word.isAssignment():
+			t.Kind = TokenAssignment
+		default:
+			prefix = false
+		}
+		if !fn(t) {
+			return nil
+		}
+	}
+}
+
+func newToken(s string, w word) Token {
+	return Token{
+		Value:    w.value,
+		Raw:      s[w.start:w.end],
+		Pos:      w.pos,
+		Operator: w.operator,
+	}
+}
+
+// Span is a piece of a line as returned by Spans: either a token, or the
+// blanks and comments separating tokens.
+type Span struct {
+	// Raw is the text of the span. Separators are kept exactly, blanks
+	// and comments included.
+	Raw string
+
+	// Value is the text of a token with quotes and escapes removed, as
+	// returned by Split, so that tokens can be matched and rewritten
+	// without splitting them again. It is empty for separators.
+	Value string
+
+	// Offset is the byte offset of the span in the line.
+	Offset int
+
+	// Token is set if the span is a token rather than a separator.
+	Token bool
+}
+
+// Spans splits s like Lex, but returns the separators between tokens as
+// well, so that the concatenation of the Raw of all spans is exactly s. With
+// WithOperators, pipelines and lists can be edited token by token and
+// reassembled without disturbing the rest of the line.
+//
+// Tokens and separators alternate, starting with a separator that may be
+// empty. The last span is always a separator, possibly empty.
+//
+// If s ends within quotes or with a trailing backslash, or a limit is
+// exceeded, Spans returns a *ParseError along with the spans so far, the last
+// of which holds the rest of s.
+func Spans(s string, opts ...Option) ([]Span, error) {
+	l := &Lexer{cfg: newConfig(opts)}
+	l.sc = newScanner(strings.NewReader(s), &l.cfg)
+
+	var spans []Span
+	end := 0
+	for {
+		res := l.read()
+		if res.err != nil {
+			spans = append(spans, Span{Raw: s[end:], Offset: end})
+			if res.err == io.EOF {
+				return spans, nil
+			}
+			return spans, res.err
+		}
+		w := res.word
+		spans = append(spans,
+			Span{Raw: s[end:w.start], Offset: end},
+			Span{Raw: s[w.start:w.end], Value: w.value, Offset: w.start, Token: true},
+		)
+		end = w.end
+	}
+}
+
+// SegmentKind is the kind of a Segment.
+type SegmentKind uint8
+
+const (
+	// SegmentLiteral is unquoted text without pattern characters.
+	SegmentLiteral SegmentKind = iota
+
+	// SegmentQuoted is quoted or escaped text.
+	SegmentQuoted
+
+	// SegmentGlob is an unquoted run of the pattern characters *, ? and
+	// bracket expressions such as [ab].
+	SegmentGlob
+)
+
+func (k SegmentKind) String() string {
+	switch k {
+	case SegmentLiteral:
+		return "literal"
+	case SegmentQuoted:
+		return "quoted"
+	case SegmentGlob:
+		return "glob"
+	}
+	return fmt.Sprintf("SegmentKind(%d)", uint8(k))
+}
+
+// Segment is part of a token, as returned by Token.Segments.
+type Segment struct {
+	Kind SegmentKind
+
+	// Raw is the segment as written, and Value is its text with quotes
+	// and escapes removed.
+	Raw   string
+	Value string
+
+	// Offset is the byte offset of the segment in the Raw of the token.
+	Offset int
+}
+
+// Segments divides the token into literal, quoted and glob segments, so that
+// pathname patterns can be expanded, escaped or rejected. Adjacent segments
+// of the same kind are merged; concatenating the Raw of all segments gives
+// the Raw of the token, and concatenating their Value gives its Value as split
+// with the default options.
+func (t Token) Segments() []Segment {
+	var segs []Segment
+	add := func(kind SegmentKind, start, end int, value string) {
+		if n := len(segs); n > 0 && segs[n-1].Kind == kind {
+			segs[n-1].Raw = t.Raw[segs[n-1].Offset:end]
+			segs[n-1].Value += value
+			return
+		}
+		segs = append(segs, Segment{Kind: kind, Raw: t.Raw[start:end], Value: value, Offset: start})
+	}
+
+	s := t.Raw
+	lastDollar := false
+	for i := 0; i < len(s); {
+		// The quotes of $'...' and $"..." include the $. As in Split, it
+		// is dropped from $"..." and from empty $'', but kept otherwise.
+		start, dollar := i, ""
+		if s[i] == '$' && !lastDollar && i+1 < len(s) && (s[i+1] == '\'' || s[i+1] == '"') {
+			i++
+			if s[i] == '\'' {
+				dollar = "$"
+			}
+		}
+		c := s[i]
+		// In $$'', the $ belongs to $$.
+		lastDollar = c == '$' && !lastDollar
+		switch c {
+		case '\\':
+			_, size := utf8.DecodeRuneInString(s[i+1:])
+			add(SegmentQuoted, i, i+1+size, s[i+1:i+1+size])
+			i += 1 + size
+
+		case '\'':
+			end := indexByteFrom(s, i+1, '\'')
+			if end < 0 {
+				add(SegmentQuoted, start, len(s), dollar+s[i+1:])
+				return segs
+			}
+			if end == i+1 {
+				dollar = ""
+			}
+			add(SegmentQuoted, start, end+1, dollar+s[i+1:end])
+			i = end + 1
+
+		case '"':
+			var value strings.Builder
+			for i++; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					if strings.IndexByte("$`\"\\\n", s[i+1]) < 0 {
+						value.WriteByte('\\')
+					}
+					i++
+				}
+				value.WriteByte(s[i])
+			}
+			i++
+			if i > len(s) {
+				i = len(s)
+			}
+			add(SegmentQuoted, start, i, value.String())
+
+		case '*', '?':
+			add(SegmentGlob, i, i+1, s[i:i+1])
+			i++
+
+		case '[':
+			if end := bracketEnd(s, i); end > 0 {
+				add(SegmentGlob, i, end, s[i:end])
+				i = end
+				continue
+			}
+			add(SegmentLiteral, i, i+1, "[")
+			i++
+
+		default:
+			_, size := utf8.DecodeRuneInString(s[i:])
+			add(SegmentLiteral, i, i+size, s[i:i+size])
+			i += size
+		}
+	}
+	return segs
+}
+
+// Quoting is the way a token, or part of one, is quoted.
+type Quoting uint8
+
+const (
+	// QuotingBare is unquoted text, possibly with backslash escapes.
+	QuotingBare Quoting = iota
+
+	// QuotingSingle is text within single quotes.
+	QuotingSingle
+
+	// QuotingDouble is text within double quotes, including Bash's $"..."
+	// quoting.
+	QuotingDouble
+
+	// QuotingANSIC is text within Bash's $'...' quoting.
+	QuotingANSIC
+
+	// QuotingMixed is a token made of several parts, such as a'b'"c" or
+	// 'a''b'. It is never the Quoting of a QuotePart.
+	QuotingMixed
+)
+
+func (q Quoting) String() string {
+	switch q {
+	case QuotingBare:
+		return "bare"
+	case QuotingSingle:
+		return "single"
+	case QuotingDouble:
+		return "double"
+	case QuotingANSIC:
+		return "ansi-c"
+	case QuotingMixed:
+		return "mixed"
+	}
+	return fmt.Sprintf("Quoting(%d)", uint8(q))
+}
+
+// QuotePart is a run of bare text or a single quoted string within a token,
+// as returned by Token.QuoteParts.
+type QuotePart struct {
+	Quoting Quoting
+
+	// Raw is the part as written, with its quotes.
+	Raw string
+
+	// Offset is the byte offset of the part in the Raw of the token.
+	Offset int
+}
+
+// QuoteParts divides the token into its bare runs and quoted strings, so that
+// linters can warn about fragile constructs such as quotes that end and
+// restart within a word. Adjacent quoted strings are separate parts, even if
+// they use the same quotes; concatenating the Raw of all parts gives the Raw
+// of the token. A quoted string left open runs to the end of the token.
+func (t Token) QuoteParts() []QuotePart {
+	var parts []QuotePart
+	add := func(q Quoting, start, end int) {
+		if n := len(parts); n > 0 && q == QuotingBare && parts[n-1].Quoting == QuotingBare {
+			parts[n-1].Raw = t.Raw[parts[n-1].Offset:end]
+			return
+		}
+		parts = append(parts, QuotePart{Quoting: q, Raw: t.Raw[start:end], Offset: start})
+	}
+
+	s := t.Raw
+	for i := 0; i < len(s); {
+		start := i
+		switch {
+		case s[i] == '\\':
+			_, size := utf8.DecodeRuneInString(s[i+1:])
+			i += 1 + size
+			add(QuotingBare, start, i)
+
+		case s[i] == '\'':
+			i = quoteEnd(s, i+1, '\'', false)
+			add(QuotingSingle, start, i)
+
+		case s[i] == '"':
+			i = quoteEnd(s, i+1, '"', true)
+			add(QuotingDouble, start, i)
+
+		case strings.HasPrefix(s[i:], "$'"):
+			i = quoteEnd(s, i+2, '\'', true)
+			add(QuotingANSIC, start, i)
+
+		case strings.HasPrefix(s[i:], `$"`):
+			i = quoteEnd(s, i+2, '"', true)
+			add(QuotingDouble, start, i)
+
+		default:
+			_, size := utf8.DecodeRuneInString(s[i:])
+			i += size
+			add(QuotingBare, start, i)
+		}
+	}
+	return parts
+}
+
+// quoteEnd returns the index just past the closing quote of a string starting
+// at s[i], or len(s) if it is not closed. If escapes is set, a backslash
+// escapes the next byte.
+func quoteEnd(s string, i int, quote byte, escapes bool) int {
+	for ; i < len(s); i++ {
+		switch {
+		case s[i] == quote:
+			return i + 1
+		case s[i] == '\\' && escapes:
+			i++
+		}
+	}
+	return len(s)
+}
+
+// Quoting reports how the token is quoted: as a whole by one of its
+// QuoteParts, or QuotingMixed if it has several. An empty token is bare.
+func (t Token) Quoting() Quoting {
+	parts := t.QuoteParts()
+	switch len(parts) {
+	case 0:
+		return QuotingBare
+	case 1:
+		return parts[0].Quoting
+	}
+	return QuotingMixed
+}
+
+// bracketEnd returns the index just past the bracket expression starting at
+// s[i], or 0 if the [ does not begin one.
+func bracketEnd(s string, i int) int {
+	j := i + 1
+	if j < len(s) && (s[j] == '!' || s[j] == '^') {
+		j++
+	}
+	if j < len(s) && s[j] == ']' {
+		j++
+	}
+	for ; j < len(s); j++ {
+		switch s[j] {
+		case ']':
+			return j + 1
+		case '\\', '\'', '"', ' ', '\t', '\n':
+			return 0
+		}
+	}
+	return 0
+}
@@ -0,0 +1,71 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"strings"
+)
+
+// Tokenizer splits words from a string one at a time, following the same
+// rules as Split. Only as much of the string is scanned as the words
+// returned so far require, so callers that need only the first few words,
+// such as the command name, can stop early.
+//
+// A Tokenizer is not safe for concurrent use.
+type Tokenizer struct {
+	s string
+	l Lexer
+}
+
+// NewTokenizer returns a Tokenizer for s.
+func NewTokenizer(s string, opts ...Option) *Tokenizer {
+	t := &Tokenizer{s: s, l: Lexer{cfg: newConfig(opts)}}
+	t.l.sc = newScanner(strings.NewReader(s), &t.l.cfg)
+	return t
+}
+
+// Reset discards the state of the Tokenizer and makes it split s, keeping its
+// options and reusing its buffers.
+func (t *Tokenizer) Reset(s string) {
+	t.s = s
+	t.l.sc.in.(*strings.Reader).Reset(s)
+	t.l.sc.reset(t.l.sc.in)
+	t.l.err = nil
+	t.l.pos = Position{}
+	t.l.end = Position{}
+}
+
+// Next returns the next word. Errors are reported as by Lexer.Next: at the
+// end of the input, Next returns io.EOF, or a *ParseError if the input ends
+// within quotes or with a trailing backslash.
+func (t *Tokenizer) Next() (string, error) {
+	res := t.l.read()
+	t.l.update(res)
+	return res.word.value, res.err
+}
+
+// Pos returns the position of the start of the word most recently returned by
+// Next.
+func (t *Tokenizer) Pos() Position {
+	return t.l.pos
+}
+
+// End returns the position just past the end of the word most recently
+// returned by Next.
+func (t *Tokenizer) End() Position {
+	return t.l.end
+}
+
+// Raw returns the word most recently returned by Next as written in the
+// string, with quotes and escapes intact.
+func (t *Tokenizer) Raw() string {
+	return t.s[t.l.pos.Offset:t.l.end.Offset]
+}
+
+// Rest returns the part of the string that has not been scanned yet. After
+// a word ending in a blank, the blank has been scanned.
+func (t *Tokenizer) Rest() string {
+	return t.s[t.l.sc.pos.Offset:]
+}
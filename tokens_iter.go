@@ -0,0 +1,43 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.23
+
+package shlex
+
+import (
+	"io"
+	"iter"
+	"strings"
+)
+
+// Tokens returns an iterator over the words of s, split as by Split, for use
+// with range:
+//
+//	for w, err := range shlex.Tokens(line) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// Words are scanned as the loop asks for them, so no slice of all words is
+// built and breaking out of the loop early stops scanning. If s ends within
+// quotes or with a trailing backslash, the incomplete final word is yielded
+// first, followed by a *ParseError as from Lexer.Next.
+func Tokens(s string, opts ...Option) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		l := &Lexer{cfg: newConfig(opts)}
+		l.sc = newScanner(strings.NewReader(s), &l.cfg)
+		for {
+			res := l.read()
+			if res.err == io.EOF {
+				return
+			}
+			if !yield(res.word.value, res.err) || res.err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"io"
+	"strings"
+)
+
+// TranscriptEntry is a command of a shell session transcript, along with its
+// output.
+type TranscriptEntry struct {
+	// Line is the line number of the command's prompt, starting at 1.
+	Line int
+
+	// Command is the command as written after the prompt. Continuation
+	// lines are included, separated by newlines and without their prompt.
+	Command string
+
+	// Args is the command split into words, with line continuations
+	// removed.
+	Args []string
+
+	// Output holds the lines following the command up to the next prompt,
+	// each ending with a newline.
+	Output string
+}
+
+// ParseTranscript parses a shell session transcript, as found in READMEs:
+//
+//	$ echo 'hello world'
+//	hello world
+//	$ ls \
+//	>   /tmp
+//
+// A command is a line starting with the "$ " prompt. If the command ends
+// with a backslash or within quotes, it continues on the next line, where a
+// leading "> " prompt is removed. All other lines are output; lines before
+// the first prompt are ignored.
+func ParseTranscript(r io.Reader) ([]TranscriptEntry, error) {
+	var entries []TranscriptEntry
+	var output strings.Builder
+	flush := func() {
+		if len(entries) > 0 {
+			entries[len(entries)-1].Output = output.String()
+		}
+		output.Reset()
+	}
+
+	continuing := false
+	err := readLines(r, func(n int, line string) error {
+		if continuing {
+			e := &entries[len(entries)-1]
+			e.Command += "\n" + strings.TrimPrefix(line, "> ")
+			continuing = incomplete(e.Command)
+			return nil
+		}
+		if line == "$" || strings.HasPrefix(line, "$ ") {
+			flush()
+			cmd := strings.TrimPrefix(strings.TrimPrefix(line, "$"), " ")
+			entries = append(entries, TranscriptEntry{Line: n, Command: cmd})
+			continuing = incomplete(cmd)
+			return nil
+		}
+		output.WriteString(line + "\n")
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	flush()
+
+	for i := range entries {
+		entries[i].Args, _, _ = CommandAt(entries[i].Command, 1)
+	}
+	return entries, nil
+}
+
+// incomplete reports whether cmd ends within quotes or with a backslash.
+func incomplete(cmd string) bool {
+	_, err := splitWords(cmd, nil)
+	return err != nil
+}
@@ -0,0 +1,145 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// View is a word of a line found by Views. Only its location is recorded;
+// the word with quotes and escapes removed is computed by Value on first use.
+type View struct {
+	line       string
+	start, end int
+	plain      bool
+
+	value string
+	done  bool
+}
+
+// Raw returns the word as written, with quotes and escapes intact.
+func (v *View) Raw() string {
+	return v.line[v.start:v.end]
+}
+
+// Offset returns the byte offset of the word in the line.
+func (v *View) Offset() int {
+	return v.start
+}
+
+// Quoted reports whether the word contains quotes or escapes, i.e. whether
+// Value differs from Raw.
+func (v *View) Quoted() bool {
+	return !v.plain
+}
+
+// Value returns the word as Split would, computing it the first time it is
+// called. For words without quotes or escapes, it does not allocate.
+func (v *View) Value() string {
+	if v.plain {
+		return v.Raw()
+	}
+	if !v.done {
+		v.done = true
+		if words, _ := splitWords(v.Raw(), nil); len(words) > 0 {
+			v.value = words[0].value
+		}
+	}
+	return v.value
+}
+
+// Views returns the words of line as views, following the same rules as
+// Split, without unquoting them. It is cheaper than Split when only some
+// words are needed, e.g. just the first one of each line.
+func Views(line string) []View {
+	var views []View
+	for i := 0; ; {
+		v, next, ok := nextView(line, i)
+		if !ok {
+			return views
+		}
+		views = append(views, v)
+		i = next
+	}
+}
+
+// FirstView returns the first word of line, as returned by Views, and
+// whether there is one. It does not look beyond the first word.
+func FirstView(line string) (View, bool) {
+	v, _, ok := nextView(line, 0)
+	return v, ok
+}
+
+// nextView returns the view of the word starting at or after line[i] and the
+// index just past it.
+func nextView(line string, i int) (View, int, bool) {
+	// Skip blanks and comments.
+	for i < len(line) {
+		r, size := utf8.DecodeRuneInString(line[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+			continue
+		case r == '#':
+			n := strings.IndexByte(line[i:], '\n')
+			if n < 0 {
+				return View{}, len(line), false
+			}
+			i += n
+			continue
+		}
+		break
+	}
+	if i >= len(line) {
+		return View{}, i, false
+	}
+
+	v := View{line: line, start: i, plain: true}
+	for i < len(line) {
+		switch line[i] {
+		case '\\':
+			v.plain = false
+			i++
+			if i < len(line) {
+				_, size := utf8.DecodeRuneInString(line[i:])
+				i += size
+			}
+			continue
+
+		case '\'':
+			v.plain = false
+			n := strings.IndexByte(line[i+1:], '\'')
+			if n < 0 {
+				i = len(line)
+			} else {
+				i += n + 2
+			}
+			continue
+
+		case '"':
+			v.plain = false
+			for i++; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' {
+					i++
+				}
+			}
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(line[i:])
+		if unicode.IsSpace(r) {
+			break
+		}
+		i += size
+	}
+	if i > len(line) {
+		i = len(line)
+	}
+	v.end = i
+	return v, i, true
+}
@@ -0,0 +1,65 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"strings"
+)
+
+// QuoteWindows quotes s as a single argument of a Windows command line, as
+// parsed by CommandLineToArgvW and the Microsoft C runtime. It is the
+// quoting used by os/exec on Windows.
+//
+// QuoteWindows does not quote for cmd.exe, which has its own metacharacters.
+func QuoteWindows(s string) string {
+	if len(s) == 0 {
+		return `""`
+	}
+	if !strings.ContainsAny(s, " \t\n\v\"") {
+		return s
+	}
+
+	var b strings.Builder
+	b.WriteByte('"')
+	slashes := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '\\':
+			slashes++
+		case '"':
+			// Backslashes preceding a quote are doubled, and the quote
+			// itself is escaped.
+			b.WriteString(strings.Repeat(`\`, slashes+1))
+			slashes = 0
+		default:
+			slashes = 0
+		}
+		b.WriteByte(c)
+	}
+	// Backslashes preceding the closing quote are doubled.
+	b.WriteString(strings.Repeat(`\`, slashes))
+	b.WriteByte('"')
+	return b.String()
+}
+
+// JoinWindows quotes each argument with QuoteWindows and joins them with
+// spaces into a Windows command line.
+func JoinWindows(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = QuoteWindows(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// JoinFor joins argv into a command line for the operating system goos, as
+// named by runtime.GOOS: JoinWindows for "windows", and Join for all others.
+func JoinFor(goos string, argv []string) string {
+	if goos == "windows" {
+		return JoinWindows(argv)
+	}
+	return Join(argv)
+}
@@ -0,0 +1,125 @@
+// Copyright 2017-2020 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shlex
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrNoForLoop is returned by ForWords for scripts without a for
+	// loop.
+	ErrNoForLoop = errors.New("no for loop")
+
+	// ErrNoCase is returned by CasePatterns for scripts without a case
+	// statement.
+	ErrNoCase = errors.New("no case statement")
+
+	// ErrUnterminatedCase is returned by CasePatterns for a case
+	// statement without esac.
+	ErrUnterminatedCase = errors.New("unterminated case statement")
+)
+
+// ForWords returns the variable name and the word list of the first for loop
+// of script, such as f and *.go, "a b" and $x for
+//
+//	for f in *.go "a b" $x; do
+//
+// The words are tokens, so their quoting and glob characters, as told apart
+// by Token.Segments, are kept for analysis; nothing is expanded. The list
+// ends at an operator such as ; or at a newline. If the loop has no in, and
+// thus iterates over "$@", words is nil; an empty list after in is not nil.
+func ForWords(script string) (name string, words []Token, err error) {
+	tokens, err := Lex(script, WithOperators(true))
+	if err != nil {
+		return "", nil, err
+	}
+
+	m := minifier{cmd: true}
+	for i, t := range tokens {
+		m.newline(script, tokens, i)
+		if !m.cmd || t.Operator || t.Raw != "for" {
+			m.token(t.Raw, t.Operator)
+			continue
+		}
+		if i+1 >= len(tokens) || !isName(tokens[i+1].Raw) {
+			break
+		}
+		name = tokens[i+1].Raw
+		rest := tokens[i+2:]
+		if len(rest) == 0 || rest[0].Raw != "in" || newlineBetween(script, tokens[i+1], rest[0]) {
+			return name, nil, nil
+		}
+		words = []Token{}
+		for j := 1; j < len(rest) && !rest[j].Operator && !newlineBetween(script, rest[j-1], rest[j]); j++ {
+			words = append(words, rest[j])
+		}
+		return name, words, nil
+	}
+	return "", nil, ErrNoForLoop
+}
+
+// newline moves m past the newline, if any, between tokens[i] of s and the
+// token before it, which ends a command unless it joins as in Minify.
+func (m *minifier) newline(s string, tokens []Token, i int) {
+	if i > 0 && !m.joins && newlineBetween(s, tokens[i-1], tokens[i]) {
+		m.token(";", true)
+	}
+}
+
+// newlineBetween reports whether there is a newline between the tokens a
+// and b of s.
+func newlineBetween(s string, a, b Token) bool {
+	return strings.Contains(s[a.End():b.Start()], "\n")
+}
+
+// CasePatterns returns the word tested by the first case statement of
+// script, and the patterns of each of its clauses. For
+//
+//	case $1 in
+//	start|restart) run ;;
+//	'-h'|*help) usage ;;
+//	esac
+//
+// it returns $1 and the clauses [start restart] and ['-h' *help]. As with
+// ForWords, the patterns are tokens, with quoting and glob characters kept.
+// Nested case statements within the clauses are skipped.
+func CasePatterns(script string) (word Token, clauses [][]Token, err error) {
+	tokens, err := Lex(script, WithOperators(true))
+	if err != nil {
+		return Token{}, nil, err
+	}
+
+	m := minifier{cmd: true}
+	var patterns []Token
+	found := false
+	for i, t := range tokens {
+		m.newline(script, tokens, i)
+		before := m
+		m.token(t.Raw, t.Operator)
+		switch {
+		case before.depth > 1:
+		case before.caseWords == 2 && before.depth == 0:
+			word = t
+			found = true
+		case before.pattern && before.depth == 1:
+			switch {
+			case !t.Operator && t.Raw != "esac":
+				patterns = append(patterns, t)
+			case t.Raw == ")":
+				clauses = append(clauses, patterns)
+				patterns = nil
+			}
+		}
+		if before.depth == 1 && m.depth == 0 {
+			return word, clauses, nil
+		}
+	}
+	if !found {
+		return Token{}, nil, ErrNoCase
+	}
+	return word, clauses, ErrUnterminatedCase
+}